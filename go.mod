@@ -4,10 +4,17 @@ go 1.13
 
 require (
 	cloud.google.com/go v0.47.0
+	github.com/fsnotify/fsnotify v1.4.7
 	github.com/go-redis/redis v6.15.6+incompatible
+	github.com/golang/protobuf v1.3.4
 	github.com/julienschmidt/httprouter v1.2.0
 	github.com/onsi/ginkgo v1.10.3 // indirect
 	github.com/onsi/gomega v1.7.1 // indirect
+	github.com/prometheus/client_golang v1.2.1
+	github.com/spf13/cast v1.3.0
 	github.com/spf13/viper v1.4.0
+	go.opentelemetry.io/otel v0.6.0
+	go.opentelemetry.io/otel/exporters/otlp v0.6.0
 	golang.org/x/text v0.3.2
+	google.golang.org/grpc v1.27.1
 )
@@ -0,0 +1,142 @@
+// Package catalog exchanges the translatable fields of the stories with the
+// translators as XLIFF 1.2 or 2.0, gettext PO and CSV documents, and reads back
+// the translated documents.
+//
+// Each field is a unit keyed as "<story ID>:<component UID>:<field>", for
+// instance "42:0b6d0e4c-...:title" for the title of a step. The key is the unit
+// ID in XLIFF, the msgctxt in PO and the first column in CSV.
+package catalog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// ImportStream receives the stories translated through the documents to be
+// saved on Storyblok.
+const ImportStream = "translations_import"
+
+// Unit is a translatable field of a story.
+type Unit struct {
+	StoryID int
+	UID     string
+	Field   string
+	Source  string
+	Target  string
+}
+
+// Key returns the identifier of the unit in the documents.
+func (u Unit) Key() string {
+	return fmt.Sprintf("%d:%s:%s", u.StoryID, u.UID, u.Field)
+}
+
+// Document holds the units of a set of stories to be translated from the
+// source into the target language.
+type Document struct {
+	SourceLang string
+	TargetLang string
+	Units      []Unit
+}
+
+// StoryIDs returns the IDs of the stories of the units, in order of appearance.
+func (d *Document) StoryIDs() []int {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, u := range d.Units {
+		if !seen[u.StoryID] {
+			seen[u.StoryID] = true
+			ids = append(ids, u.StoryID)
+		}
+	}
+	return ids
+}
+
+// Units returns the units of the translatable fields of the story.
+func Units(story types.Story) []Unit {
+	var units []Unit
+	for _, f := range story.TextFields() {
+		if f.Value == "" {
+			continue
+		}
+		u := Unit{StoryID: story.ID, UID: story.Content.UID, Field: f.Path, Source: f.Value}
+		switch {
+		case strings.HasPrefix(f.Path, "steps."):
+			parts := strings.SplitN(f.Path, ".", 3)
+			u.UID, u.Field = parts[1], parts[2]
+		case strings.HasPrefix(f.Path, "ingredients."):
+			u.UID = story.Content.Ingredients.UID
+		}
+		units = append(units, u)
+	}
+	return units
+}
+
+// path returns the path of the unit field inside the story content.
+func path(story types.Story, u Unit) string {
+	if strings.HasPrefix(u.Field, "ingredients.") || u.UID == story.Content.UID {
+		return u.Field
+	}
+	return "steps." + u.UID + "." + u.Field
+}
+
+// Apply sets the targets of the units of the story as its field values.
+// Units without target are an error, the source text would be saved as
+// translation, as well as stale units whose source text differs from the one
+// of the story. The units must cover every translatable field of the story,
+// since the fields left out would be saved in the source language.
+func Apply(story *types.Story, units []Unit) error {
+	sources := make(map[string]string)
+	for _, f := range story.TextFields() {
+		sources[f.Path] = f.Value
+	}
+
+	covered := make(map[string]bool)
+	for _, u := range units {
+		if u.StoryID == story.ID {
+			covered[u.Key()] = true
+		}
+	}
+	for _, u := range Units(*story) {
+		if !covered[u.Key()] {
+			return fmt.Errorf("unit %s is missing", u.Key())
+		}
+	}
+
+	for _, u := range units {
+		if u.StoryID != story.ID {
+			continue
+		}
+		if u.Target == "" {
+			return fmt.Errorf("unit %s has no target", u.Key())
+		}
+		p := path(*story, u)
+		source, ok := sources[p]
+		if !ok {
+			return fmt.Errorf("unit %s: field not found", u.Key())
+		}
+		if source != u.Source {
+			return fmt.Errorf("unit %s is stale, the source text changed", u.Key())
+		}
+		err := story.SetTextField(p, u.Target)
+		if err != nil {
+			return fmt.Errorf("unit %s: %s", u.Key(), err)
+		}
+	}
+	return nil
+}
+
+// parseUnit returns the unit with the given key.
+func parseUnit(key, source, target string) (Unit, error) {
+	parts := strings.SplitN(key, ":", 3)
+	if len(parts) != 3 {
+		return Unit{}, fmt.Errorf("unit %s: not a story field", key)
+	}
+	storyID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Unit{}, fmt.Errorf("unit %s: not a story field", key)
+	}
+	return Unit{StoryID: storyID, UID: parts[1], Field: parts[2], Source: source, Target: target}, nil
+}
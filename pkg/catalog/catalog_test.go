@@ -0,0 +1,62 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func testStory(t *testing.T) types.Story {
+	ss, err := types.ParseStories([]byte(`{
+		"id": 42,
+		"content": {
+			"_uid": "root",
+			"title": "Pasta & pomodoro",
+			"steps": [{"_uid": "s1", "title": "Cuocere", "content": "Cuocere la pasta"}],
+			"ingredients": {"_uid": "ingr", "ingredients": [{"name": "Sale", "unit": "g"}]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ss[0]
+}
+
+func TestApply(t *testing.T) {
+	units := Units(testStory(t))
+	for i := range units {
+		units[i].Target = "EN " + units[i].Source
+	}
+
+	story := testStory(t)
+	err := Apply(&story, units)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Content.Steps[0].Title != "EN Cuocere" {
+		t.Errorf("expected the step title translated, got %q", story.Content.Steps[0].Title)
+	}
+
+	// the source text changed after the export
+	story = testStory(t)
+	story.Content.Steps[0].Title = "Bollire"
+	err = Apply(&story, units)
+	if err == nil || !strings.Contains(err.Error(), "42:s1:title is stale") {
+		t.Errorf("expected the step title to be stale, got %v", err)
+	}
+
+	units[0].Target = ""
+	story = testStory(t)
+	err = Apply(&story, units)
+	if err == nil || !strings.Contains(err.Error(), "has no target") {
+		t.Errorf("expected an error for units without target, got %v", err)
+	}
+
+	// a field left out would be saved in the source language
+	story = testStory(t)
+	err = Apply(&story, units[1:])
+	if err == nil || !strings.Contains(err.Error(), units[0].Key()+" is missing") {
+		t.Errorf("expected an error for the missing unit, got %v", err)
+	}
+}
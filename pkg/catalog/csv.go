@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// byte order mark, for the spreadsheets to read the file as UTF-8
+const bom = "\ufeff"
+
+// ExportCSV encodes the document as CSV with the key, source and target of
+// each unit, under a header naming the languages as "key,it,en".
+func ExportCSV(d *Document) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(bom)
+
+	w := csv.NewWriter(&b)
+	w.Write([]string{"key", d.SourceLang, d.TargetLang})
+	for _, u := range d.Units {
+		w.Write([]string{u.Key(), u.Source, u.Target})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// ParseCSV decodes a CSV document exported by ExportCSV. Spreadsheets saving
+// with semicolons as separators are supported.
+func ParseCSV(data []byte) (*Document, error) {
+	data = bytes.TrimPrefix(data, []byte(bom))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	if first := strings.SplitN(string(data), "\n", 2)[0]; !strings.Contains(first, ",") && strings.Contains(first, ";") {
+		r.Comma = ';'
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty CSV document")
+	}
+
+	header := records[0]
+	if len(header) < 3 || header[0] != "key" {
+		return nil, errors.New(`the CSV header must be "key,<source language>,<target language>"`)
+	}
+
+	d := &Document{SourceLang: header[1], TargetLang: header[2]}
+	for i, rec := range records[1:] {
+		u, err := parseUnit(rec[0], rec[1], rec[2])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %s", i+2, err)
+		}
+		d.Units = append(d.Units, u)
+	}
+	return d, nil
+}
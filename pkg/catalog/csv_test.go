@@ -0,0 +1,44 @@
+package catalog
+
+import "testing"
+
+func TestCSVRoundTrip(t *testing.T) {
+	units := Units(testStory(t))
+	units[0].Target = "Pasta, tomato \"sauce\"\nand basil"
+
+	doc, err := ExportCSV(&Document{SourceLang: "it", TargetLang: "en", Units: units})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := ParseCSV(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SourceLang != "it" || d.TargetLang != "en" {
+		t.Errorf("expected it-en, got %s-%s", d.SourceLang, d.TargetLang)
+	}
+	if len(d.Units) != len(units) {
+		t.Fatalf("expected %d units, got %d", len(units), len(d.Units))
+	}
+	for i, u := range d.Units {
+		if u != units[i] {
+			t.Errorf("expected %+v, got %+v", units[i], u)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	d, err := ParseCSV([]byte("key;it;en\n42:root:title;Pasta;Pasta dish\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Units) != 1 || d.Units[0].Target != "Pasta dish" {
+		t.Errorf("expected the semicolon separated unit, got %+v", d.Units)
+	}
+
+	_, err = ParseCSV([]byte("id,source,target\n42:root:title,Pasta,Pasta\n"))
+	if err == nil {
+		t.Error("expected an error for a missing header")
+	}
+}
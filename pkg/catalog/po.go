@@ -0,0 +1,196 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExportPO encodes the document as a gettext PO file, the key of each unit
+// being its msgctxt.
+func ExportPO(d *Document) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Storyblok stories to be translated from %s to %s.\n", d.SourceLang, d.TargetLang)
+	b.WriteString("msgid \"\"\nmsgstr \"\"\n")
+	for _, h := range []string{
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+		"Language: " + d.TargetLang,
+		"X-Source-Language: " + d.SourceLang,
+	} {
+		fmt.Fprintf(&b, "\"%s\\n\"\n", poEscape(h))
+	}
+
+	for _, u := range d.Units {
+		fmt.Fprintf(&b, "\n#: story/%d\n", u.StoryID)
+		writePOString(&b, "msgctxt", u.Key())
+		writePOString(&b, "msgid", u.Source)
+		writePOString(&b, "msgstr", u.Target)
+	}
+	return b.Bytes(), nil
+}
+
+// writePOString writes the keyword with its string, split after each newline.
+func writePOString(b *bytes.Buffer, keyword, s string) {
+	if !strings.Contains(s, "\n") {
+		fmt.Fprintf(b, "%s \"%s\"\n", keyword, poEscape(s))
+		return
+	}
+	fmt.Fprintf(b, "%s \"\"\n", keyword)
+	lines := strings.SplitAfter(s, "\n")
+	for _, line := range lines {
+		if line != "" {
+			fmt.Fprintf(b, "\"%s\"\n", poEscape(line))
+		}
+	}
+}
+
+var poEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+func poEscape(s string) string {
+	return poEscaper.Replace(s)
+}
+
+// poEntry is a message of a PO file being parsed.
+type poEntry struct {
+	ctxt, id, str *string
+	fuzzy         bool
+	line          int
+}
+
+// ParsePO decodes a gettext PO file. Fuzzy entries are taken as not translated.
+func ParsePO(data []byte) (*Document, error) {
+	d := &Document{}
+	var (
+		e     poEntry
+		field *string
+		n     int
+	)
+
+	flush := func() error {
+		defer func() { e, field = poEntry{}, nil }()
+		if e.id == nil {
+			return nil
+		}
+		if e.ctxt == nil {
+			if *e.id == "" && e.str != nil {
+				// header entry
+				d.SourceLang, d.TargetLang = poHeaders(*e.str)
+				return nil
+			}
+			return fmt.Errorf("line %d: entry without msgctxt", e.line)
+		}
+
+		target := ""
+		if e.str != nil && !e.fuzzy {
+			target = *e.str
+		}
+		u, err := parseUnit(*e.ctxt, *e.id, target)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", e.line, err)
+		}
+		d.Units = append(d.Units, u)
+		return nil
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), len(data)+1)
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		// comments open the next entry
+		if line == "" || (strings.HasPrefix(line, "#") && e.str != nil) {
+			err := flush()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#,"):
+			e.fuzzy = e.fuzzy || strings.Contains(line, "fuzzy")
+		case strings.HasPrefix(line, "#"):
+			// translator comments, references and obsolete entries
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return nil, fmt.Errorf("line %d: string outside of an entry", n)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", n, err)
+			}
+			*field += s
+		default:
+			sep := strings.IndexByte(line, ' ')
+			if sep < 0 {
+				return nil, fmt.Errorf("line %d: unexpected %q", n, line)
+			}
+			keyword := line[:sep]
+			s, err := strconv.Unquote(strings.TrimSpace(line[sep:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", n, err)
+			}
+
+			// a new entry starts without blank line
+			if (keyword == "msgctxt" || keyword == "msgid") && e.id != nil {
+				err := flush()
+				if err != nil {
+					return nil, err
+				}
+			}
+			if e.line == 0 {
+				e.line = n
+			}
+
+			switch keyword {
+			case "msgctxt":
+				e.ctxt = &s
+				field = e.ctxt
+			case "msgid":
+				e.id = &s
+				field = e.id
+			case "msgstr", "msgstr[0]":
+				e.str = &s
+				field = e.str
+			default:
+				// plural forms are not used by the stories
+				field = new(string)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	err := flush()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// poHeaders returns the source and target languages from the header entry.
+func poHeaders(header string) (source, target string) {
+	for _, h := range strings.Split(header, "\n") {
+		sep := strings.IndexByte(h, ':')
+		if sep < 0 {
+			continue
+		}
+		v := strings.TrimSpace(h[sep+1:])
+		switch strings.TrimSpace(h[:sep]) {
+		case "Language":
+			target = v
+		case "X-Source-Language":
+			source = v
+		}
+	}
+	return source, target
+}
@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPORoundTrip(t *testing.T) {
+	units := Units(testStory(t))
+	units[0].Source = "Riga \"uno\"\nriga due"
+
+	doc, err := ExportPO(&Document{SourceLang: "it", TargetLang: "en", Units: units})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(doc), "msgctxt \"42:s1:content\"\n") {
+		t.Errorf("expected the step content keyed by story, step uid and field in:\n%s", doc)
+	}
+
+	d, err := ParsePO(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SourceLang != "it" || d.TargetLang != "en" {
+		t.Errorf("expected it-en, got %s-%s", d.SourceLang, d.TargetLang)
+	}
+	if len(d.Units) != len(units) {
+		t.Fatalf("expected %d units, got %d", len(units), len(d.Units))
+	}
+	for i, u := range d.Units {
+		if u != units[i] {
+			t.Errorf("expected %+v, got %+v", units[i], u)
+		}
+	}
+}
+
+func TestParsePO(t *testing.T) {
+	d, err := ParsePO([]byte(`msgid ""
+msgstr ""
+"Language: en\n"
+"X-Source-Language: it\n"
+
+# reviewed by the agency
+msgctxt "42:root:title"
+msgid "Pasta"
+msgstr ""
+"Pasta "
+"dish"
+#, fuzzy
+msgctxt "42:root:summary"
+msgid "Buona"
+msgstr "Good"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Unit{
+		{StoryID: 42, UID: "root", Field: "title", Source: "Pasta", Target: "Pasta dish"},
+		// fuzzy entries are not translated
+		{StoryID: 42, UID: "root", Field: "summary", Source: "Buona"},
+	}
+	if len(d.Units) != len(expected) {
+		t.Fatalf("expected %d units, got %+v", len(expected), d.Units)
+	}
+	for i, u := range expected {
+		if d.Units[i] != u {
+			t.Errorf("expected %+v, got %+v", u, d.Units[i])
+		}
+	}
+
+	_, err = ParsePO([]byte("msgid \"Pasta\"\nmsgstr \"Pasta\"\n"))
+	if err == nil {
+		t.Error("expected an error for an entry without msgctxt")
+	}
+}
@@ -0,0 +1,228 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// supported versions of XLIFF
+const (
+	XLIFF12 = "1.2"
+	XLIFF20 = "2.0"
+)
+
+const (
+	ns12 = "urn:oasis:names:tc:xliff:document:1.2"
+	ns20 = "urn:oasis:names:tc:xliff:document:2.0"
+)
+
+// --- XLIFF 1.2
+type xliff12 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string   `xml:"version,attr"`
+	Files   []file12 `xml:"file"`
+}
+
+type file12 struct {
+	Original   string   `xml:"original,attr"`
+	SourceLang string   `xml:"source-language,attr"`
+	TargetLang string   `xml:"target-language,attr,omitempty"`
+	Datatype   string   `xml:"datatype,attr"`
+	Units      []unit12 `xml:"body>trans-unit"`
+}
+
+type unit12 struct {
+	ID     string  `xml:"id,attr"`
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+}
+
+// --- XLIFF 2.0
+type xliff20 struct {
+	XMLName    xml.Name `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version    string   `xml:"version,attr"`
+	SourceLang string   `xml:"srcLang,attr"`
+	TargetLang string   `xml:"trgLang,attr,omitempty"`
+	Files      []file20 `xml:"file"`
+}
+
+type file20 struct {
+	ID    string   `xml:"id,attr"`
+	Units []unit20 `xml:"unit"`
+}
+
+type unit20 struct {
+	ID string `xml:"id,attr"`
+	// segments and ignorable whitespace, along with the notes and other elements
+	Parts []part20 `xml:",any"`
+}
+
+type part20 struct {
+	XMLName xml.Name
+	Source  string  `xml:"source"`
+	Target  *string `xml:"target"`
+}
+
+// text joins the sources and the targets of the segments of the unit, split by
+// the CAT tools, with the whitespace between them.
+func (u unit20) text() (string, *string) {
+	var source, target strings.Builder
+	translated := false
+	for _, p := range u.Parts {
+		if p.XMLName.Local != "segment" && p.XMLName.Local != "ignorable" {
+			continue
+		}
+		source.WriteString(p.Source)
+		switch {
+		case p.Target != nil:
+			target.WriteString(*p.Target)
+			translated = translated || p.XMLName.Local == "segment"
+		case p.XMLName.Local == "ignorable":
+			// ignorable whitespace goes along with the targets as well
+			target.WriteString(p.Source)
+		}
+	}
+	if !translated {
+		return source.String(), nil
+	}
+	t := target.String()
+	return source.String(), &t
+}
+
+// ---
+
+// ExportXLIFF encodes the document as XLIFF of the given version, grouping the
+// units in a file per story.
+func ExportXLIFF(d *Document, version string) ([]byte, error) {
+	var v interface{}
+	switch version {
+	case XLIFF12:
+		doc := xliff12{Version: XLIFF12}
+		for _, units := range byStory(d.Units) {
+			f := file12{
+				Original:   "story/" + strconv.Itoa(units[0].StoryID),
+				SourceLang: d.SourceLang,
+				TargetLang: d.TargetLang,
+				Datatype:   "plaintext",
+			}
+			for _, u := range units {
+				f.Units = append(f.Units, unit12{ID: u.Key(), Source: u.Source, Target: target(u)})
+			}
+			doc.Files = append(doc.Files, f)
+		}
+		v = doc
+	case XLIFF20:
+		doc := xliff20{Version: XLIFF20, SourceLang: d.SourceLang, TargetLang: d.TargetLang}
+		for _, units := range byStory(d.Units) {
+			f := file20{ID: "story-" + strconv.Itoa(units[0].StoryID)}
+			for _, u := range units {
+				f.Units = append(f.Units, unit20{ID: u.Key(), Parts: []part20{{
+					XMLName: xml.Name{Local: "segment"},
+					Source:  u.Source,
+					Target:  target(u),
+				}}})
+			}
+			doc.Files = append(doc.Files, f)
+		}
+		v = doc
+	default:
+		return nil, fmt.Errorf("unsupported XLIFF version %q", version)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func target(u Unit) *string {
+	if u.Target == "" {
+		return nil
+	}
+	return &u.Target
+}
+
+func byStory(units []Unit) [][]Unit {
+	var groups [][]Unit
+	index := make(map[int]int)
+	for _, u := range units {
+		i, ok := index[u.StoryID]
+		if !ok {
+			i = len(groups)
+			index[u.StoryID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], u)
+	}
+	return groups
+}
+
+// ParseXLIFF decodes an XLIFF 1.2 or 2.0 document.
+func ParseXLIFF(data []byte) (*Document, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	err := xml.Unmarshal(data, &root)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{}
+	switch root.XMLName.Space {
+	case ns12:
+		var doc xliff12
+		err = xml.Unmarshal(data, &doc)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range doc.Files {
+			if d.SourceLang == "" {
+				d.SourceLang, d.TargetLang = f.SourceLang, f.TargetLang
+			}
+			for _, tu := range f.Units {
+				u, err := parseUnit(tu.ID, tu.Source, deref(tu.Target))
+				if err != nil {
+					return nil, err
+				}
+				d.Units = append(d.Units, u)
+			}
+		}
+	case ns20:
+		var doc xliff20
+		err = xml.Unmarshal(data, &doc)
+		if err != nil {
+			return nil, err
+		}
+		d.SourceLang, d.TargetLang = doc.SourceLang, doc.TargetLang
+		for _, f := range doc.Files {
+			for _, tu := range f.Units {
+				source, target := tu.text()
+				u, err := parseUnit(tu.ID, source, deref(target))
+				if err != nil {
+					return nil, err
+				}
+				d.Units = append(d.Units, u)
+			}
+		}
+	default:
+		return nil, errors.New("not an XLIFF 1.2 or 2.0 document")
+	}
+	return d, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,101 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestXLIFFRoundTrip(t *testing.T) {
+	story := testStory(t)
+
+	for _, version := range []string{XLIFF12, XLIFF20} {
+		units := Units(story)
+		ids := make(map[string]bool)
+		for _, u := range units {
+			ids[u.Key()] = true
+		}
+		for _, id := range []string{"42:root:title", "42:s1:content", "42:ingr:ingredients.0.name"} {
+			if !ids[id] {
+				t.Errorf("%s: expected unit %s, got %v", version, id, units)
+			}
+		}
+
+		doc, err := ExportXLIFF(&Document{SourceLang: "it", TargetLang: "en", Units: units}, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(doc), "Pasta &amp; pomodoro") {
+			t.Errorf("%s: expected escaped source in:\n%s", version, doc)
+		}
+
+		// the translator fills in the targets
+		for i := range units {
+			units[i].Target = "EN " + units[i].Source
+		}
+		doc, err = ExportXLIFF(&Document{SourceLang: "it", TargetLang: "en", Units: units}, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := ParseXLIFF(doc)
+		if err != nil {
+			t.Fatalf("%s: %s", version, err)
+		}
+		if d.SourceLang != "it" || d.TargetLang != "en" {
+			t.Errorf("%s: expected it-en, got %s-%s", version, d.SourceLang, d.TargetLang)
+		}
+		if ids := d.StoryIDs(); len(ids) != 1 || ids[0] != 42 {
+			t.Errorf("%s: expected story 42, got %v", version, ids)
+		}
+
+		translated := testStory(t)
+		err = Apply(&translated, d.Units)
+		if err != nil {
+			t.Fatalf("%s: %s", version, err)
+		}
+		c := translated.Content
+		if c.Title != "EN Pasta & pomodoro" || c.Steps[0].Content != "EN Cuocere la pasta" ||
+			c.Ingredients.Ingredients[0].Name != "EN Sale" {
+			t.Errorf("%s: targets not applied: %+v", version, c)
+		}
+	}
+}
+
+func TestParseXLIFFInvalid(t *testing.T) {
+	_, err := ParseXLIFF([]byte(`<xliff version="1.2"><file/></xliff>`))
+	if err == nil {
+		t.Error("expected an error without the XLIFF namespace")
+	}
+
+	_, err = ParseXLIFF([]byte(`<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+		<file original="x" source-language="it" datatype="plaintext"><body>
+			<trans-unit id="title"><source>Titolo</source></trans-unit>
+		</body></file>
+	</xliff>`))
+	if err == nil {
+		t.Error("expected an error for a unit not mapping to a story field")
+	}
+}
+
+func TestParseXLIFFSegments(t *testing.T) {
+	d, err := ParseXLIFF([]byte(`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="it" trgLang="en">
+		<file id="story-42">
+			<unit id="42:root:title">
+				<notes><note>recipe title</note></notes>
+				<segment><source>Pasta.</source><target>Pasta.</target></segment>
+				<ignorable><source> </source></ignorable>
+				<segment><source>Buona.</source><target>Good.</target></segment>
+			</unit>
+		</file>
+	</xliff>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Units) != 1 {
+		t.Fatalf("expected 1 unit, got %v", d.Units)
+	}
+	u := d.Units[0]
+	if u.Source != "Pasta. Buona." || u.Target != "Pasta. Good." {
+		t.Errorf("expected the segments joined, got %q and %q", u.Source, u.Target)
+	}
+}
@@ -0,0 +1,156 @@
+// Package client is a Go client of the polygo server HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// Client sends requests to a polygo server.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	HTTPClient *http.Client
+}
+
+// Error is an error reply of the server.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("polygo: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// New returns a client of the server at baseURL authenticating with the API key.
+func New(baseURL string, apiKey string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		HTTPClient: &http.Client{},
+	}
+}
+
+// Translate translates a story synchronously, returning the translations by language.
+func (c *Client) Translate(ctx context.Context, req types.TranslateStoryRequest) (map[string]types.Story, error) {
+	var resp types.TranslateStoryReply
+	err := c.do(ctx, "POST", "/v1/translate", req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Translations, nil
+}
+
+// CreateJob submits the stories to be translated into the target languages in background.
+func (c *Client) CreateJob(ctx context.Context, storyIDs []int, targets []string) (*job.Job, error) {
+	req := types.JobRequest{
+		StoryIDs: storyIDs,
+		Targets:  targets,
+	}
+
+	var j job.Job
+	err := c.do(ctx, "POST", "/v1/jobs", req, &j)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// Job returns the state of the job with the given ID.
+func (c *Client) Job(ctx context.Context, id string) (*job.Job, error) {
+	var j job.Job
+	err := c.do(ctx, "GET", "/v1/jobs/"+url.PathEscape(id), nil, &j)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// WaitJob polls the job at the given interval until all its translations are
+// either saved or failed, or the context is done.
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (*job.Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		j, err := c.Job(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if j.Done() {
+			return j, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return j, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnqueueStories puts the stories on the translation stream, returning the stream message IDs.
+func (c *Client) EnqueueStories(ctx context.Context, stories []types.Story) ([]string, error) {
+	var resp struct {
+		MessageIDs []string `json:"message_ids"`
+	}
+	err := c.do(ctx, "POST", "/stream/stories", stories, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.MessageIDs, nil
+}
+
+// do sends the request with body encoded as JSON and decodes the response into v.
+func (c *Client) do(ctx context.Context, method string, path string, body interface{}, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		js, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(js)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		e := &Error{StatusCode: res.StatusCode}
+		if json.NewDecoder(res.Body).Decode(e) != nil || e.Message == "" {
+			e.Message = res.Status
+		}
+		return e
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
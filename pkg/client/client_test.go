@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestCreateJob(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/jobs" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("expected bearer token, got %q", got)
+		}
+		var req types.JobRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.StoryIDs) != 1 || req.StoryIDs[0] != 42 {
+			t.Errorf("unexpected story IDs %v", req.StoryIDs)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job.Job{ID: "abc", Targets: req.Targets})
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL+"/", "key")
+	j, err := c.CreateJob(context.Background(), []int{42}, []string{"en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.ID != "abc" {
+		t.Errorf("expected job abc, got %q", j.ID)
+	}
+}
+
+func TestError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"job not found"}`))
+	}))
+	defer ts.Close()
+
+	_, err := New(ts.URL, "").Job(context.Background(), "abc")
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if e.StatusCode != http.StatusNotFound || e.Message != "job not found" {
+		t.Errorf("unexpected error %+v", e)
+	}
+}
+
+func TestWaitJob(t *testing.T) {
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		state := job.Translating
+		if polls == 3 {
+			state = job.Saved
+		}
+		json.NewEncoder(w).Encode(job.Job{
+			ID:      "abc",
+			Stories: map[string]map[string]job.Status{"42": {"en": {State: state}}},
+		})
+	}))
+	defer ts.Close()
+
+	j, err := New(ts.URL, "").WaitJob(context.Background(), "abc", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 || !j.Done() {
+		t.Errorf("expected job done after 3 polls, got %d", polls)
+	}
+}
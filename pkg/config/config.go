@@ -0,0 +1,271 @@
+// Package config loads the typed configuration of the services from the
+// config file and the POLYGO_ environment variables.
+//
+// The fields of the configuration structs are bound to the settings through
+// their tags:
+//
+//	config:"name"      name of the setting, or of the section for nested structs
+//	default:"value"    value used when the setting is missing
+//	required:"true"    the setting must be set
+//	secret:"true"      the value is redacted from the dumps
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+const envPrefix = "polygo"
+
+const redacted = "<redacted>"
+
+// Error lists the problems found loading a configuration.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n\t" + strings.Join(e.Problems, "\n\t")
+}
+
+// validator is implemented by the configurations checking more than the
+// presence of the required settings.
+type validator interface {
+	validate() []string
+}
+
+// Init sets up the configuration to be read from the config file in the
+// working directory, if any, and from the environment.
+func Init() error {
+	viper.SetConfigName("config")
+	viper.AddConfigPath(".")
+	viper.SetEnvPrefix(envPrefix)
+	viper.AutomaticEnv()
+	replacer := strings.NewReplacer(".", "_")
+	viper.SetEnvKeyReplacer(replacer)
+
+	err := viper.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		// settings come from the environment only
+		return nil
+	}
+	return err
+}
+
+// Load fills cfg, a pointer to a configuration struct, with the current
+// settings and validates them, returning an *Error listing all the problems.
+func Load(cfg interface{}) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: cannot load into %T", cfg)
+	}
+
+	var problems []string
+	load(v.Elem(), "", &problems)
+	if len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
+
+func load(v reflect.Value, prefix string, problems *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("config")
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		fv := v.Field(i)
+
+		if f.Type.Kind() == reflect.Struct {
+			load(fv, key+".", problems)
+			continue
+		}
+
+		raw := viper.Get(key)
+		if raw == nil || raw == "" {
+			raw = f.Tag.Get("default")
+		}
+		fv.Set(reflect.Zero(f.Type))
+		if raw != "" {
+			err := set(fv, raw)
+			if err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s (%s): %s", key, EnvVar(key), err))
+				continue
+			}
+		}
+		if f.Tag.Get("required") == "true" && isZero(fv) {
+			*problems = append(*problems, fmt.Sprintf("%s (%s) is required", key, EnvVar(key)))
+		}
+	}
+
+	if val, ok := v.Addr().Interface().(validator); ok {
+		for _, p := range val.validate() {
+			*problems = append(*problems, prefix+p)
+		}
+	}
+}
+
+// set converts the raw setting to the type of the field.
+func set(fv reflect.Value, raw interface{}) error {
+	var (
+		val interface{}
+		err error
+	)
+	switch fv.Interface().(type) {
+	case string:
+		val, err = cast.ToStringE(raw)
+	case bool:
+		val, err = cast.ToBoolE(raw)
+	case int:
+		val, err = cast.ToIntE(raw)
+	case float64:
+		val, err = cast.ToFloat64E(raw)
+	case time.Duration:
+		val, err = cast.ToDurationE(raw)
+	case []string:
+		val, err = cast.ToStringSliceE(raw)
+	case map[string]int:
+		val, err = cast.ToStringMapIntE(raw)
+	case map[string][]string:
+		val, err = cast.ToStringMapStringSliceE(raw)
+	case map[string]map[string]string:
+		val, err = toStringMapStringMapE(raw)
+	case []APIKey:
+		val, err = toAPIKeysE(raw)
+	default:
+		return fmt.Errorf("unsupported setting type %s", fv.Type())
+	}
+	if err != nil {
+		return err
+	}
+	fv.Set(reflect.ValueOf(val))
+	return nil
+}
+
+func toStringMapStringMapE(raw interface{}) (map[string]map[string]string, error) {
+	m, err := cast.ToStringMapE(raw)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]map[string]string, len(m))
+	for k, v := range m {
+		res[k], err = cast.ToStringMapStringE(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", k, err)
+		}
+	}
+	return res, nil
+}
+
+// toAPIKeysE converts the list of keys of the config file, or its JSON
+// encoding set in the environment.
+func toAPIKeysE(raw interface{}) ([]APIKey, error) {
+	if js, ok := raw.(string); ok {
+		var keys []APIKey
+		err := json.Unmarshal([]byte(js), &keys)
+		if err != nil {
+			return nil, fmt.Errorf("decoding keys: %s", err)
+		}
+		return keys, nil
+	}
+
+	list, err := cast.ToSliceE(raw)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]APIKey, 0, len(list))
+	for i, item := range list {
+		m, err := cast.ToStringMapE(item)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %s", i, err)
+		}
+		var k APIKey
+		k.Key, err = cast.ToStringE(m["key"])
+		if err != nil {
+			return nil, fmt.Errorf("[%d].key: %s", i, err)
+		}
+		if m["scopes"] != nil {
+			k.Scopes, err = cast.ToStringSliceE(m["scopes"])
+			if err != nil {
+				return nil, fmt.Errorf("[%d].scopes: %s", i, err)
+			}
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func isZero(fv reflect.Value) bool {
+	if fv.Kind() == reflect.Map || fv.Kind() == reflect.Slice {
+		return fv.Len() == 0
+	}
+	return fv.IsZero()
+}
+
+// Watch watches the config file, calling apply with a newly loaded
+// configuration of the same type as cfg each time the file changes. Changes
+// leaving the configuration invalid are logged and ignored.
+func Watch(cfg interface{}, apply func(next interface{})) {
+	t := reflect.TypeOf(cfg).Elem()
+
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		next := reflect.New(t).Interface()
+		err := Load(next)
+		if err != nil {
+			logging.Warnf("Ignoring change of %s: %s", e.Name, err)
+			return
+		}
+		logging.Infof("Configuration reloaded:\n%s", Dump(next))
+		apply(next)
+	})
+	viper.WatchConfig()
+}
+
+// EnvVar returns the environment variable overriding the setting.
+func EnvVar(key string) string {
+	return strings.ToUpper(envPrefix + "_" + strings.Replace(key, ".", "_", -1))
+}
+
+// Dump returns the settings of the configuration, one per line, with the
+// secrets redacted.
+func Dump(cfg interface{}) string {
+	var b strings.Builder
+	dump(&b, reflect.Indirect(reflect.ValueOf(cfg)), "")
+	return b.String()
+}
+
+func dump(b *strings.Builder, v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("config")
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		fv := v.Field(i)
+
+		if f.Type.Kind() == reflect.Struct {
+			dump(b, fv, key+".")
+			continue
+		}
+
+		var val interface{} = fv.Interface()
+		if f.Tag.Get("secret") == "true" && !isZero(fv) {
+			val = redacted
+		}
+		fmt.Fprintf(b, "%s: %v\n", key, val)
+	}
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoad(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.AutomaticEnv()
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	os.Setenv("POLYGO_SERVER_AUTH_KEYS", `[{"key": "k1", "scopes": ["read", "enqueue"]}]`)
+	defer os.Unsetenv("POLYGO_SERVER_AUTH_KEYS")
+	viper.Set("redis.host", "redis:6379")
+	viper.Set("storyblok.host", "storyblok:8070")
+	viper.Set("storyblok.grpc_host", "storyblok:8071")
+	viper.Set("translator.grpc_host", "translator:8091")
+	viper.Set("server.job_timeout", "45s")
+	viper.Set("server.auth.webhook_secret", "shh")
+
+	var cfg Server
+	err := Load(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != "8080" {
+		t.Errorf("expected default port 8080, got %q", cfg.Server.Port)
+	}
+	if cfg.Server.JobTimeout != 45*time.Second {
+		t.Errorf("expected job timeout 45s, got %s", cfg.Server.JobTimeout)
+	}
+	if len(cfg.Server.Auth.Keys) != 1 || len(cfg.Server.Auth.Keys[0].Scopes) != 2 {
+		t.Errorf("expected 2 scopes for key k1, got %v", cfg.Server.Auth.Keys)
+	}
+
+	dump := Dump(&cfg)
+	for _, secret := range []string{"k1", "shh"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("expected %q to be redacted from dump:\n%s", secret, dump)
+		}
+	}
+	if !strings.Contains(dump, "storyblok.grpc_host: storyblok:8071\n") {
+		t.Errorf("expected storyblok.grpc_host in dump:\n%s", dump)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+redis:
+  host: redis:6379
+storyblok:
+  host: storyblok:8070
+  grpc_host: storyblok:8071
+translator:
+  grpc_host: translator:8091
+server:
+  auth:
+    keys:
+      - key: Mixed-Case-Key
+        scopes: [read, enqueue]
+      - key: AdminKey
+        scopes: [admin]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Server
+	err = Load(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []APIKey{
+		{Key: "Mixed-Case-Key", Scopes: []string{"read", "enqueue"}},
+		{Key: "AdminKey", Scopes: []string{"admin"}},
+	}
+	if !reflect.DeepEqual(cfg.Server.Auth.Keys, expected) {
+		t.Errorf("expected keys %v, got %v", expected, cfg.Server.Auth.Keys)
+	}
+
+	viper.Set("server.auth.keys", []interface{}{map[string]interface{}{"scopes": []interface{}{"read"}}})
+	err = Load(&cfg)
+	cerr, ok := err.(*Error)
+	if !ok || len(cerr.Problems) != 1 || cerr.Problems[0] != "server.auth.keys[0].key is required" {
+		t.Errorf("expected missing key problem, got %v", err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("storyblok.token", "token")
+	viper.Set("storyblok.space", "my-space")
+	viper.Set("tracing.sample_ratio", 2)
+	viper.Set("review.enabled", "maybe")
+
+	var cfg Storyblok
+	err := Load(&cfg)
+	cerr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+
+	expected := []string{
+		"storyblok.oauth (POLYGO_STORYBLOK_OAUTH) is required",
+		"storyblok.space must be a numeric space ID",
+		"review.enabled (POLYGO_REVIEW_ENABLED)",
+		"redis.host (POLYGO_REDIS_HOST) is required",
+		"tracing.sample_ratio must be between 0 and 1",
+	}
+	if len(cerr.Problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %q", len(expected), cerr.Problems)
+	}
+	for i, p := range expected {
+		if !strings.HasPrefix(cerr.Problems[i], p) {
+			t.Errorf("expected problem %q, got %q", p, cerr.Problems[i])
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	dir, err := ioutil.TempDir("", "polygo-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+
+	write := func(content string) {
+		err := ioutil.WriteFile(file, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("redis:\n  host: redis:6379\ntranslator:\n  max_concurrent_requests: 5\n")
+	viper.SetConfigFile(file)
+	err = viper.ReadInConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied := make(chan *Translator, 10)
+	Watch(&Translator{}, func(next interface{}) {
+		applied <- next.(*Translator)
+	})
+
+	// invalid changes are ignored
+	write("redis:\n  host: redis:6379\ntranslator:\n  max_concurrent_requests: -1\n")
+	time.Sleep(100 * time.Millisecond)
+	write("redis:\n  host: redis:6379\ntranslator:\n  max_concurrent_requests: 10\n")
+
+	select {
+	case cfg := <-applied:
+		if cfg.Translator.MaxConcurrentRequests != 10 {
+			t.Errorf("expected 10 max concurrent requests, got %d", cfg.Translator.MaxConcurrentRequests)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the change to be applied")
+	}
+}
+
+func TestLoadBudget(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("redis.host", "redis:6379")
+	viper.Set("translator.budget.daily", map[string]interface{}{"en": 1000, "??": 10})
+	viper.Set("translator.budget.monthly", map[string]interface{}{"fr": -1})
+	viper.Set("translator.budget.fallback", "google")
+
+	var cfg Translator
+	err := Load(&cfg)
+	cerr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+
+	expected := []string{
+		`translator.budget.daily: invalid language "??"`,
+		"translator.budget.monthly.fr must not be negative",
+		"translator.budget.fallback must differ from backend",
+	}
+	if len(cerr.Problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %q", len(expected), cerr.Problems)
+	}
+	for i, p := range expected {
+		if cerr.Problems[i] != p {
+			t.Errorf("expected problem %q, got %q", p, cerr.Problems[i])
+		}
+	}
+
+	viper.Set("translator.budget.monthly", map[string]interface{}{"fr": 5000})
+	viper.Set("translator.budget.daily", map[string]interface{}{"en": 1000})
+	viper.Set("translator.budget.fallback", "gtx")
+	err = Load(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Translator.Backend != "google" || cfg.Translator.Budget.Daily["en"] != 1000 || cfg.Translator.Budget.Monthly["fr"] != 5000 {
+		t.Errorf("unexpected translation settings %+v", cfg.Translator)
+	}
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+// Redis settings shared by all the services.
+type Redis struct {
+	Host string `config:"host" required:"true"`
+}
+
+// Tracing settings shared by all the services.
+type Tracing struct {
+	// address of the OpenTelemetry collector, tracing is off when empty
+	Endpoint string `config:"endpoint"`
+	// ratio of the traces sampled, none when 0
+	SampleRatio float64 `config:"sample_ratio" default:"1"`
+}
+
+func (t *Tracing) validate() []string {
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		return []string{fmt.Sprintf("sample_ratio must be between 0 and 1, got %g", t.SampleRatio)}
+	}
+	return nil
+}
+
+// Log settings shared by all the services.
+type Log struct {
+	Level string `config:"level" default:"info"`
+}
+
+func (l *Log) validate() []string {
+	if _, err := logging.ParseLevel(l.Level); err != nil {
+		return []string{"level: " + err.Error()}
+	}
+	return nil
+}
+
+// ParsedLevel returns the parsed log level.
+func (l *Log) ParsedLevel() logging.Level {
+	lv, _ := logging.ParseLevel(l.Level)
+	return lv
+}
+
+// APIKey is an API key of the server with the scopes granted to it. Keys are
+// listed rather than mapped to their scopes since viper lowercases map keys.
+type APIKey struct {
+	Key    string   `json:"key"`
+	Scopes []string `json:"scopes"`
+}
+
+// Auth settings of the server API.
+type Auth struct {
+	Keys          []APIKey `config:"keys" secret:"true"`
+	WebhookSecret string   `config:"webhook_secret" secret:"true"`
+}
+
+func (a *Auth) validate() []string {
+	var problems []string
+	for i, k := range a.Keys {
+		if k.Key == "" {
+			problems = append(problems, fmt.Sprintf("keys[%d].key is required", i))
+		}
+	}
+	return problems
+}
+
+// HTTP settings of the server.
+type HTTP struct {
+	Port string `config:"port" default:"8080"`
+	// timeouts of the requests, the server defaults are used when zero
+	JobTimeout       time.Duration `config:"job_timeout"`
+	TranslateTimeout time.Duration `config:"translate_timeout"`
+	Auth             Auth          `config:"auth"`
+}
+
+// StoryblokClient settings to reach the storyblok service.
+type StoryblokClient struct {
+	Host     string `config:"host" required:"true"`
+	GRPCHost string `config:"grpc_host" required:"true"`
+}
+
+// TranslatorClient settings to reach the translator service.
+type TranslatorClient struct {
+	GRPCHost string `config:"grpc_host" required:"true"`
+}
+
+// Server is the configuration of the API server.
+type Server struct {
+	Server     HTTP             `config:"server"`
+	Redis      Redis            `config:"redis"`
+	Storyblok  StoryblokClient  `config:"storyblok"`
+	Translator TranslatorClient `config:"translator"`
+	Tracing    Tracing          `config:"tracing"`
+	Log        Log              `config:"log"`
+}
+
+// StoryblokSpace settings to access the Storyblok space.
+type StoryblokSpace struct {
+	Token string `config:"token" required:"true" secret:"true"`
+	OAuth string `config:"oauth" required:"true" secret:"true"`
+	Space string `config:"space" required:"true"`
+	// target languages by content type
+	Targets map[string][]string `config:"targets"`
+	// reset the translated flag of the stories at startup
+	ResetTranslated bool `config:"reset_translated"`
+	// languages of the translations saved on Storyblok
+	Languages []string `config:"languages" default:"en fr"`
+	// pause between two writes, respecting the Storyblok api rate limit
+	SaveInterval time.Duration `config:"save_interval" default:"350ms"`
+	// record the translations as diffs instead of saving them on Storyblok
+	DryRun bool `config:"dry_run"`
+}
+
+func (s *StoryblokSpace) validate() []string {
+	var problems []string
+	if _, err := strconv.Atoi(s.Space); s.Space != "" && err != nil {
+		problems = append(problems, fmt.Sprintf("space must be a numeric space ID, got %q", s.Space))
+	}
+	for _, code := range s.Languages {
+		if _, err := language.Parse(code); err != nil {
+			problems = append(problems, fmt.Sprintf("languages: invalid language %q", code))
+		}
+	}
+	return problems
+}
+
+// Review settings of the translations.
+type Review struct {
+	Enabled bool `config:"enabled"`
+	// hold for review only the translations with fields failing the quality check
+	LowConfidenceOnly bool `config:"low_confidence_only"`
+}
+
+// Storyblok is the configuration of the storyblok service.
+type Storyblok struct {
+	Storyblok StoryblokSpace `config:"storyblok"`
+	Review    Review         `config:"review"`
+	Redis     Redis          `config:"redis"`
+	Tracing   Tracing        `config:"tracing"`
+	Log       Log            `config:"log"`
+}
+
+// Translation settings of the translator service.
+type Translation struct {
+	// language of the stories coming from Storyblok
+	Source string `config:"source" default:"it"`
+	// target languages by source language, each pair is translated by its own consumer
+	Pairs map[string][]string `config:"pairs"`
+	// fixed translations of source terms by language pair, as "it-en"
+	Glossaries map[string]map[string]string `config:"glossaries"`
+	// max requests in flight to the translation service, unlimited when zero
+	MaxConcurrentRequests int `config:"max_concurrent_requests"`
+	// translation backend, google (Cloud Translation) or gtx (Google web endpoint)
+	Backend string  `config:"backend" default:"google"`
+	Budget  Budget  `config:"budget"`
+	Quality Quality `config:"quality"`
+}
+
+// Quality check of the translations, translated back into the source language.
+type Quality struct {
+	Enabled bool `config:"enabled"`
+	// min similarity of the fields translated back with their source text
+	Threshold float64 `config:"threshold" default:"0.6"`
+}
+
+func (q *Quality) validate() []string {
+	if q.Threshold < 0 || q.Threshold > 1 {
+		return []string{fmt.Sprintf("threshold must be between 0 and 1, got %g", q.Threshold)}
+	}
+	return nil
+}
+
+// backends known to the translator
+var backends = map[string]bool{"google": true, "gtx": true}
+
+// Budget limits the characters sent to the translation backend by target language.
+type Budget struct {
+	// max characters a day and a month by target language, unlimited when missing
+	Daily   map[string]int `config:"daily"`
+	Monthly map[string]int `config:"monthly"`
+	// backend translating once a budget is used up, the consumers of the
+	// language pause until the budget renews when empty
+	Fallback string `config:"fallback"`
+}
+
+func (b *Budget) validate() []string {
+	var problems []string
+	for setting, limits := range map[string]map[string]int{"daily": b.Daily, "monthly": b.Monthly} {
+		for code, limit := range limits {
+			if _, err := language.Parse(code); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: invalid language %q", setting, code))
+			}
+			if limit < 0 {
+				problems = append(problems, fmt.Sprintf("%s.%s must not be negative", setting, code))
+			}
+		}
+	}
+	if b.Fallback != "" && !backends[b.Fallback] {
+		problems = append(problems, fmt.Sprintf("fallback: unknown backend %q", b.Fallback))
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func (t *Translation) validate() []string {
+	var problems []string
+	check := func(setting string, code string) {
+		if _, err := language.Parse(code); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid language %q", setting, code))
+		}
+	}
+
+	check("source", t.Source)
+	for from, targets := range t.Pairs {
+		check("pairs", from)
+		for _, to := range targets {
+			check("pairs."+from, to)
+		}
+	}
+	for pair := range t.Glossaries {
+		langs := strings.Split(pair, "-")
+		if len(langs) != 2 {
+			problems = append(problems, fmt.Sprintf("glossaries: %q is not a language pair as \"it-en\"", pair))
+			continue
+		}
+		check("glossaries", langs[0])
+		check("glossaries", langs[1])
+	}
+	if t.MaxConcurrentRequests < 0 {
+		problems = append(problems, "max_concurrent_requests must not be negative")
+	}
+	if !backends[t.Backend] {
+		problems = append(problems, fmt.Sprintf("backend: unknown backend %q", t.Backend))
+	}
+	if t.Budget.Fallback != "" && t.Budget.Fallback == t.Backend {
+		problems = append(problems, "budget.fallback must differ from backend")
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// Translator is the configuration of the translator service.
+type Translator struct {
+	Translator Translation `config:"translator"`
+	Redis      Redis       `config:"redis"`
+	Tracing    Tracing     `config:"tracing"`
+	Log        Log         `config:"log"`
+}
+
+// Ctl is the configuration of the polygoctl admin command.
+type Ctl struct {
+	Redis Redis `config:"redis"`
+}
@@ -0,0 +1,83 @@
+// Package consumer runs the stream consumers of a service, starting and
+// stopping them as the configuration changes.
+package consumer
+
+import (
+	"sync"
+)
+
+// Consumer of a stream, reading in the consumer group Group until the stop
+// channel is closed. Run should check the stop channel between batches of
+// messages, leaving the messages not acknowledged pending in the group.
+type Consumer struct {
+	Group string
+	// Config describes the settings of the consumer, a consumer whose settings
+	// change is restarted
+	Config string
+	Run    func(stop <-chan struct{})
+}
+
+type running struct {
+	config string
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// Set of consumers running, one for each consumer group.
+type Set struct {
+	mu       sync.Mutex
+	running  map[string]*running
+	stopping map[string]*running
+}
+
+// NewSet returns an empty set of consumers.
+func NewSet() *Set {
+	return &Set{
+		running:  make(map[string]*running),
+		stopping: make(map[string]*running),
+	}
+}
+
+// Apply starts the consumers not running yet and stops the consumers of the
+// groups no longer listed. A consumer replacing another one of the same group
+// starts once the previous one has returned.
+func (s *Set) Apply(consumers []Consumer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[string]Consumer, len(consumers))
+	for _, c := range consumers {
+		wanted[c.Group] = c
+	}
+
+	for group, r := range s.running {
+		if c, ok := wanted[group]; !ok || c.Config != r.config {
+			close(r.stop)
+			delete(s.running, group)
+			s.stopping[group] = r
+		}
+	}
+
+	for group, c := range wanted {
+		if _, ok := s.running[group]; ok {
+			continue
+		}
+		r := &running{
+			config: c.Config,
+			stop:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+		prev := s.stopping[group]
+		delete(s.stopping, group)
+		s.running[group] = r
+
+		go func(run func(stop <-chan struct{})) {
+			defer close(r.done)
+			if prev != nil {
+				// never run two consumers of the same group at once
+				<-prev.done
+			}
+			run(r.stop)
+		}(c.Run)
+	}
+}
@@ -0,0 +1,81 @@
+package consumer
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the starts and stops of the consumers.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) consumer(group, config string) Consumer {
+	return Consumer{
+		Group:  group,
+		Config: config,
+		Run: func(stop <-chan struct{}) {
+			r.add("start " + group + " " + config)
+			<-stop
+			// finish the batch at hand
+			time.Sleep(10 * time.Millisecond)
+			r.add("stop " + group + " " + config)
+		},
+	}
+}
+
+func (r *recorder) add(e string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) wait(t *testing.T, n int) []string {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		if len(r.events) >= n {
+			events := append([]string(nil), r.events...)
+			r.mu.Unlock()
+			return events
+		}
+		r.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d events, got %q", n, r.events)
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	var r recorder
+	s := NewSet()
+
+	s.Apply([]Consumer{r.consumer("a", "v1")})
+	r.wait(t, 1)
+
+	// changing the settings of a restarts it after the previous one is done
+	s.Apply([]Consumer{r.consumer("a", "v2"), r.consumer("b", "v1")})
+	events := r.wait(t, 4)
+
+	var stopA1, startA2 int
+	for i, e := range events {
+		switch e {
+		case "stop a v1":
+			stopA1 = i
+		case "start a v2":
+			startA2 = i
+		}
+	}
+	if startA2 < stopA1 {
+		t.Errorf("expected a v2 to start after a v1 stopped, got %q", events)
+	}
+
+	// unchanged consumers keep running, removed ones stop
+	s.Apply([]Consumer{r.consumer("a", "v2")})
+	events = r.wait(t, 5)
+	if events[4] != "stop b v1" {
+		t.Errorf("expected b to stop, got %q", events)
+	}
+}
@@ -0,0 +1,183 @@
+// Package dryrun records the translations that storyblok would save when
+// running in dry-run mode, as per-field diffs browsable through the server.
+package dryrun
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+const (
+	// Stream receives a diff for each translation not saved.
+	Stream = "dry_run"
+	// approximate number of diffs kept on the stream
+	maxDiffs = 10000
+)
+
+var ErrNotFound = errors.New("diff not found")
+
+// FieldDiff holds the source text of a story field along with its translation
+// saved on Storyblok and the new one.
+type FieldDiff struct {
+	Path        string `json:"path"`
+	Source      string `json:"source"`
+	Current     string `json:"current"`
+	Translation string `json:"translation"`
+	Changed     bool   `json:"changed"`
+}
+
+// Diff is a translation of a story not saved on Storyblok.
+type Diff struct {
+	ID        string      `json:"id"`
+	StoryID   int         `json:"story_id"`
+	Lang      string      `json:"lang"`
+	Job       string      `json:"job,omitempty"`
+	Fields    []FieldDiff `json:"fields"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// NewDiff compares the current translation of the source story in the given
+// language, empty if missing, with the new translation.
+func NewDiff(source, current, translation types.Story, lang string) *Diff {
+	cur := make(map[string]string)
+	for _, f := range current.TextFields() {
+		cur[f.Path] = f.Value
+	}
+	next := make(map[string]string)
+	for _, f := range translation.TextFields() {
+		next[f.Path] = f.Value
+	}
+
+	d := &Diff{
+		StoryID:   source.ID,
+		Lang:      lang,
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, f := range source.TextFields() {
+		d.Fields = append(d.Fields, FieldDiff{
+			Path:        f.Path,
+			Source:      f.Value,
+			Current:     cur[f.Path],
+			Translation: next[f.Path],
+			Changed:     cur[f.Path] != next[f.Path],
+		})
+	}
+	return d
+}
+
+// Add puts the diff on the stream, setting its ID.
+func Add(rdb *redis.Client, d *Diff) error {
+	js, err := json.Marshal(d.Fields)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"story":  d.StoryID,
+		"lang":   d.Lang,
+		"fields": js,
+		"time":   d.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if d.Job != "" {
+		values[job.Field] = d.Job
+	}
+
+	id, err := rdb.XAdd(&redis.XAddArgs{
+		Stream:       Stream,
+		MaxLenApprox: maxDiffs,
+		Values:       values,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	return nil
+}
+
+// Filter selects the diffs to list.
+type Filter struct {
+	// all the stories when zero
+	StoryID int
+	// all the languages when empty
+	Lang string
+	// max diffs returned
+	Count int
+}
+
+// List returns the diffs matching the filter, newest first.
+func List(rdb *redis.Client, f Filter) ([]Diff, error) {
+	if f.Count <= 0 {
+		f.Count = 100
+	}
+
+	diffs := []Diff{}
+	end := "+"
+	for {
+		msgs, err := rdb.XRevRangeN(Stream, end, "-", 100).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			if msg.ID == end {
+				// last message of the previous page
+				continue
+			}
+			d, err := fromMessage(msg)
+			if err != nil {
+				return nil, err
+			}
+			if (f.StoryID != 0 && d.StoryID != f.StoryID) || (f.Lang != "" && d.Lang != f.Lang) {
+				continue
+			}
+			diffs = append(diffs, *d)
+			if len(diffs) == f.Count {
+				return diffs, nil
+			}
+		}
+		if len(msgs) < 100 {
+			return diffs, nil
+		}
+		end = msgs[len(msgs)-1].ID
+	}
+}
+
+// Get returns the diff with the given ID.
+func Get(rdb *redis.Client, id string) (*Diff, error) {
+	msgs, err := rdb.XRangeN(Stream, id, id, 1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, ErrNotFound
+	}
+	return fromMessage(msgs[0])
+}
+
+func fromMessage(msg redis.XMessage) (*Diff, error) {
+	str := func(k string) string {
+		v, _ := msg.Values[k].(string)
+		return v
+	}
+	storyID, _ := strconv.Atoi(str("story"))
+	t, _ := time.Parse(time.RFC3339Nano, str("time"))
+
+	d := &Diff{
+		ID:        msg.ID,
+		StoryID:   storyID,
+		Lang:      str("lang"),
+		Job:       str(job.Field),
+		CreatedAt: t,
+	}
+	err := json.Unmarshal([]byte(str("fields")), &d.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
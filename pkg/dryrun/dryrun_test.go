@@ -0,0 +1,37 @@
+package dryrun
+
+import (
+	"testing"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestNewDiff(t *testing.T) {
+	var source, current, translation types.Story
+	source.ID = 42
+	source.Content.Title = "Pasta al pomodoro"
+	source.Content.Summary = "Un classico"
+	current.Content.Title = "Tomato pasta"
+	current.Content.Summary = "A classic"
+	translation.Content.Title = "Pasta with tomato sauce"
+	translation.Content.Summary = "A classic"
+
+	d := NewDiff(source, current, translation, "en")
+	if d.StoryID != 42 || d.Lang != "en" {
+		t.Errorf("expected story 42 in en, got %d in %s", d.StoryID, d.Lang)
+	}
+
+	fields := make(map[string]FieldDiff)
+	for _, f := range d.Fields {
+		fields[f.Path] = f
+	}
+	expected := map[string]FieldDiff{
+		"title":   {"title", "Pasta al pomodoro", "Tomato pasta", "Pasta with tomato sauce", true},
+		"summary": {"summary", "Un classico", "A classic", "A classic", false},
+	}
+	for path, fd := range expected {
+		if fields[path] != fd {
+			t.Errorf("expected %+v, got %+v", fd, fields[path])
+		}
+	}
+}
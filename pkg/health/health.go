@@ -0,0 +1,253 @@
+// Package health serves the liveness and readiness probes of the services.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check reports whether a dependency of the service is usable.
+type Check func(ctx context.Context) error
+
+// Result of a single check.
+type Result struct {
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency"`
+}
+
+// Report holds the result of each check, the service is ready only when all
+// the checks pass.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker runs the readiness checks of a service.
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	checks map[string]Check
+}
+
+// NewChecker returns a checker failing the checks lasting more than timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add registers a check under the given name.
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Run runs all the checks concurrently.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	c.mu.Lock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.Unlock()
+
+	type named struct {
+		name string
+		res  Result
+	}
+	resChan := make(chan named, len(checks))
+	for name, check := range checks {
+		go func(name string, check Check) {
+			resChan <- named{name, run(ctx, check)}
+		}(name, check)
+	}
+
+	r := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(checks)),
+	}
+	for range checks {
+		n := <-resChan
+		r.Checks[n.name] = n.res
+		if n.res.Status != StatusOK {
+			r.Status = StatusFail
+		}
+	}
+	return r
+}
+
+// run runs the check giving up once the context is done, also when the check ignores it.
+func run(ctx context.Context, check Check) Result {
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := Result{
+		Status:  StatusOK,
+		Latency: time.Since(start).String(),
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Healthz reports that the process is alive.
+func (c *Checker) Healthz(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": StatusOK})
+}
+
+// Readyz runs the checks, replying 503 when any of them fails.
+func (c *Checker) Readyz(w http.ResponseWriter, req *http.Request) {
+	r := c.Run(req.Context())
+	status := http.StatusOK
+	if r.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		logging.Errorf("%s", err)
+	}
+}
+
+// Redis checks the connection to redis.
+func Redis(rdb *redis.Client) Check {
+	return func(ctx context.Context) error {
+		return rdb.WithContext(ctx).Ping().Err()
+	}
+}
+
+// Group identifies the consumer group of a stream.
+type Group struct {
+	Stream string
+	Group  string
+}
+
+// GroupList holds the consumer groups of a service, replaced when the
+// configuration changes.
+type GroupList struct {
+	mu     sync.RWMutex
+	groups []Group
+}
+
+// NewGroupList returns a list of the given consumer groups.
+func NewGroupList(groups []Group) *GroupList {
+	return &GroupList{groups: groups}
+}
+
+// Set replaces the consumer groups.
+func (l *GroupList) Set(groups []Group) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.groups = groups
+}
+
+// Get returns the current consumer groups.
+func (l *GroupList) Get() []Group {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.groups
+}
+
+// Groups checks that the current consumer groups of the list exist.
+func Groups(rdb *redis.Client, groups *GroupList) Check {
+	return func(ctx context.Context) error {
+		c := rdb.WithContext(ctx)
+		for _, g := range groups.Get() {
+			ok, err := hasGroup(c, g)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("group %s missing on stream %s", g.Group, g.Stream)
+			}
+		}
+		return nil
+	}
+}
+
+func hasGroup(c *redis.Client, g Group) (bool, error) {
+	res, err := c.Do("XINFO", "GROUPS", g.Stream).Result()
+	if err != nil {
+		if err.Error() == "ERR no such key" {
+			return false, nil
+		}
+		return false, err
+	}
+
+	groups, ok := res.([]interface{})
+	if !ok {
+		return false, errors.New("unexpected XINFO reply")
+	}
+	for _, gr := range groups {
+		fields, _ := gr.([]interface{})
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i] == "name" && fields[i+1] == g.Group {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Cached runs the check at most once every ttl, reusing its last outcome in
+// between. It is meant for checks calling external APIs.
+func Cached(check Check, ttl time.Duration) Check {
+	var (
+		mu      sync.Mutex
+		lastErr error
+		lastRun time.Time
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !lastRun.IsZero() && time.Since(lastRun) < ttl {
+			return lastErr
+		}
+		err := check(ctx)
+		if ctx.Err() != nil {
+			// do not keep timeouts of the probe itself
+			return err
+		}
+		lastErr, lastRun = err, time.Now()
+		return err
+	}
+}
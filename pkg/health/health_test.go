@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadyz(t *testing.T) {
+	c := NewChecker(50 * time.Millisecond)
+	c.Add("ok", func(context.Context) error { return nil })
+	c.Add("broken", func(context.Context) error { return errors.New("boom") })
+	c.Add("slow", func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	c.Readyz(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", w.Code)
+	}
+	var r Report
+	err := json.NewDecoder(w.Body).Decode(&r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != StatusFail {
+		t.Errorf("expected report status fail, got %s", r.Status)
+	}
+	if r.Checks["ok"].Status != StatusOK {
+		t.Errorf("expected check ok to pass, got %+v", r.Checks["ok"])
+	}
+	if r.Checks["broken"].Error != "boom" {
+		t.Errorf("expected check broken to fail with boom, got %+v", r.Checks["broken"])
+	}
+	if r.Checks["slow"].Status != StatusFail {
+		t.Errorf("expected check slow to time out, got %+v", r.Checks["slow"])
+	}
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	check := Cached(func(context.Context) error {
+		calls++
+		return nil
+	}, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		check(context.Background())
+	}
+	if calls != 1 {
+		t.Errorf("expected the check to run once, ran %d times", calls)
+	}
+}
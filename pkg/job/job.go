@@ -0,0 +1,329 @@
+package job
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// State of the translation of a story into a language.
+type State string
+
+const (
+	Queued      State = "queued"
+	Translating State = "translating"
+	Translated  State = "translated"
+	Review      State = "review"
+	Saved       State = "saved"
+	Failed      State = "failed"
+	// DryRun translations are recorded as diffs instead of being saved.
+	DryRun State = "dry_run"
+)
+
+// Field is the name of the stream message field carrying the job ID.
+const Field = "job"
+
+// EventsStream receives an event for each state change of the jobs.
+const EventsStream = "job_events"
+
+const (
+	keyPrefix = "job:"
+	metaField = "_meta"
+	// field holding the ID of the first event of the job
+	eventsField = "_events"
+	// margin for the clock of the events stream running ahead, when the
+	// first event of the job is not recorded
+	clockSkew = 5 * time.Second
+	// time a job is kept after its last update
+	ttl = 7 * 24 * time.Hour
+	// approximate number of events kept on the stream
+	maxEvents = 100000
+)
+
+var ErrNotFound = errors.New("job not found")
+
+// Status of the translation of a story into a language.
+type Status struct {
+	State     State     `json:"state"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Job tracks the translation of a set of stories into a set of languages.
+type Job struct {
+	ID        string    `json:"id"`
+	Targets   []string  `json:"targets"`
+	CreatedAt time.Time `json:"created_at"`
+	// Stories maps story IDs to the status of each target language.
+	Stories map[string]map[string]Status `json:"stories"`
+	// FirstEvent is the ID of the first event of the job on the events stream.
+	FirstEvent string `json:"-"`
+}
+
+// EventsAfter returns the ID of the events stream the events of the job follow.
+func (j *Job) EventsAfter() string {
+	if j.FirstEvent == "" {
+		ms := j.CreatedAt.Add(-clockSkew).UnixNano() / int64(time.Millisecond)
+		return strconv.FormatInt(ms, 10) + "-0"
+	}
+	return previousID(j.FirstEvent)
+}
+
+// Done tells whether all the translations of the job are either saved, failed
+// or recorded by a dry run.
+func (j *Job) Done() bool {
+	for _, langs := range j.Stories {
+		for _, st := range langs {
+			if st.State != Saved && st.State != Failed && st.State != DryRun {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Event is a state change of the translation of a story.
+type Event struct {
+	ID      string    `json:"-"`
+	Job     string    `json:"job"`
+	StoryID int       `json:"story_id"`
+	Lang    string    `json:"lang,omitempty"`
+	State   State     `json:"state"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// EventFromMessage decodes a message of the events stream.
+func EventFromMessage(msg redis.XMessage) Event {
+	str := func(k string) string {
+		v, _ := msg.Values[k].(string)
+		return v
+	}
+	storyID, _ := strconv.Atoi(str("story"))
+	t, _ := time.Parse(time.RFC3339Nano, str("time"))
+
+	return Event{
+		ID:      msg.ID,
+		Job:     str(Field),
+		StoryID: storyID,
+		Lang:    str("lang"),
+		State:   State(str("state")),
+		Error:   str("error"),
+		Time:    t,
+	}
+}
+
+// updateScript sets the status only for the stories and languages tracked by
+// the job and publishes the state change on the events stream.
+var updateScript = redis.NewScript(`
+	if redis.call("hexists", KEYS[1], ARGV[1]) == 1 then
+		redis.call("hset", KEYS[1], ARGV[1], ARGV[2])
+		redis.call("expire", KEYS[1], ARGV[3])
+		redis.call("xadd", KEYS[2], "MAXLEN", "~", ARGV[4], "*", unpack(ARGV, 5))
+		return 1
+	end
+	return 0
+`)
+
+// Create stores a new job with all the stories queued for each target language.
+func Create(rdb *redis.Client, storyIDs []int, targets []string) (*Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	j := &Job{
+		ID:        id,
+		Targets:   targets,
+		CreatedAt: now,
+		Stories:   make(map[string]map[string]Status, len(storyIDs)),
+	}
+
+	meta, err := json.Marshal(struct {
+		ID        string    `json:"id"`
+		Targets   []string  `json:"targets"`
+		CreatedAt time.Time `json:"created_at"`
+	}{j.ID, j.Targets, j.CreatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{metaField: meta}
+	queued := Status{State: Queued, UpdatedAt: now}
+	js, err := json.Marshal(queued)
+	if err != nil {
+		return nil, err
+	}
+	for _, sid := range storyIDs {
+		langs := make(map[string]Status, len(targets))
+		for _, lang := range targets {
+			fields[field(sid, lang)] = js
+			langs[lang] = queued
+		}
+		j.Stories[strconv.Itoa(sid)] = langs
+	}
+
+	var first *redis.StringCmd
+	_, err = rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HMSet(keyPrefix+id, fields)
+		pipe.Expire(keyPrefix+id, ttl)
+		for _, sid := range storyIDs {
+			xadd := pipe.XAdd(&redis.XAddArgs{
+				Stream:       EventsStream,
+				MaxLenApprox: maxEvents,
+				Values:       eventValues(id, sid, "", Queued, "", now),
+			})
+			if first == nil {
+				first = xadd
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// record where the events of the job start
+	if first != nil {
+		j.FirstEvent = first.Val()
+		err = rdb.HSet(keyPrefix+id, eventsField, j.FirstEvent).Err()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// Get returns the job with the given ID.
+func Get(rdb *redis.Client, id string) (*Job, error) {
+	vals, err := rdb.HGetAll(keyPrefix + id).Result()
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := vals[metaField]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	var j Job
+	err = json.Unmarshal([]byte(meta), &j)
+	if err != nil {
+		return nil, err
+	}
+
+	j.FirstEvent = vals[eventsField]
+	j.Stories = make(map[string]map[string]Status)
+	for k, v := range vals {
+		if k == metaField || k == eventsField {
+			continue
+		}
+		sep := strings.LastIndex(k, ":")
+		if sep < 0 {
+			continue
+		}
+		sid, lang := k[:sep], k[sep+1:]
+
+		var st Status
+		err = json.Unmarshal([]byte(v), &st)
+		if err != nil {
+			return nil, err
+		}
+		if j.Stories[sid] == nil {
+			j.Stories[sid] = make(map[string]Status)
+		}
+		j.Stories[sid][lang] = st
+	}
+	return &j, nil
+}
+
+// Update sets the state of the translation of a story into a language. Stories
+// and languages not tracked by the job, or jobs expired, are ignored.
+func Update(rdb *redis.Client, id string, storyID int, lang string, state State, cause error) error {
+	if id == "" {
+		return nil
+	}
+
+	st := Status{
+		State:     state,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if cause != nil {
+		st.Error = cause.Error()
+	}
+	js, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+
+	argv := []interface{}{field(storyID, lang), string(js), int(ttl.Seconds()), maxEvents}
+	for k, v := range eventValues(id, storyID, lang, st.State, st.Error, st.UpdatedAt) {
+		argv = append(argv, k, v)
+	}
+
+	return updateScript.Run(
+		rdb,
+		[]string{keyPrefix + id, EventsStream}, // KEYS
+		argv...,                                // ARGV
+	).Err()
+}
+
+func eventValues(id string, storyID int, lang string, state State, cause string, t time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		Field:   id,
+		"story": storyID,
+		"lang":  lang,
+		"state": string(state),
+		"error": cause,
+		"time":  t.Format(time.RFC3339Nano),
+	}
+}
+
+// FromMessage returns the ID of the job the stream message belongs to, if any.
+func FromMessage(values map[string]interface{}) string {
+	id, _ := values[Field].(string)
+	return id
+}
+
+// previousID returns the stream ID preceding the given one.
+func previousID(id string) string {
+	sep := strings.Index(id, "-")
+	if sep < 0 {
+		return id
+	}
+	ms, err := strconv.ParseUint(id[:sep], 10, 64)
+	if err != nil {
+		return id
+	}
+	seq, err := strconv.ParseUint(id[sep+1:], 10, 64)
+	if err != nil {
+		return id
+	}
+	if seq > 0 {
+		return strconv.FormatUint(ms, 10) + "-" + strconv.FormatUint(seq-1, 10)
+	}
+	if ms == 0 {
+		return "0-0"
+	}
+	return strconv.FormatUint(ms-1, 10) + "-" + strconv.FormatUint(math.MaxUint64, 10)
+}
+
+func field(storyID int, lang string) string {
+	return strconv.Itoa(storyID) + ":" + lang
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,58 @@
+package job
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+func TestEventFromMessage(t *testing.T) {
+	now := time.Now().UTC()
+
+	// redis replies with string values
+	values := make(map[string]interface{})
+	for k, v := range eventValues("abc", 42, "fr", Failed, "quota exceeded", now) {
+		values[k] = fmt.Sprint(v)
+	}
+
+	ev := EventFromMessage(redis.XMessage{ID: "1-0", Values: values})
+	want := Event{
+		ID:      "1-0",
+		Job:     "abc",
+		StoryID: 42,
+		Lang:    "fr",
+		State:   Failed,
+		Error:   "quota exceeded",
+		Time:    now,
+	}
+	if !ev.Time.Equal(want.Time) {
+		t.Errorf("Error decoding event time: got %v, want %v", ev.Time, want.Time)
+	}
+	ev.Time = want.Time
+	if ev != want {
+		t.Errorf("Error decoding event: got %+v, want %+v", ev, want)
+	}
+}
+
+func TestEventsAfter(t *testing.T) {
+	tests := []struct {
+		first, after string
+	}{
+		{"1526919030474-3", "1526919030474-2"},
+		{"1526919030474-0", "1526919030473-18446744073709551615"},
+		{"0-0", "0-0"},
+	}
+	for _, tt := range tests {
+		j := Job{FirstEvent: tt.first}
+		if after := j.EventsAfter(); after != tt.after {
+			t.Errorf("EventsAfter for first event %s: got %s, want %s", tt.first, after, tt.after)
+		}
+	}
+
+	j := Job{CreatedAt: time.Unix(1526919030, 0)}
+	if after := j.EventsAfter(); after != "1526919025000-0" {
+		t.Errorf("EventsAfter without first event: got %s, want 1526919025000-0", after)
+	}
+}
@@ -0,0 +1,222 @@
+// Package logging writes leveled logs as JSON lines, carrying the fields
+// correlating each line to the service, stream message, story and job.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level of a log line, lines below the configured level are discarded.
+type Level int32
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+	Fatal
+)
+
+var levelNames = []string{"debug", "info", "warn", "error", "fatal"}
+
+func (l Level) String() string {
+	if l < Debug || l > Fatal {
+		return fmt.Sprintf("level(%d)", l)
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level with the given name.
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return Level(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unknown log level %q", name)
+}
+
+// Fields attached to the log lines. Common fields are service, stream, group,
+// message_id, story_id, pair (as "it-en"), lang and job.
+type Fields map[string]interface{}
+
+// Logger writes log lines carrying its fields.
+type Logger struct {
+	fields Fields
+}
+
+var (
+	mu      sync.Mutex
+	out     io.Writer = os.Stderr
+	service string
+	level   = int32(Info)
+)
+
+var root = &Logger{}
+
+// Init sets the name of the service added to each line and the level of the
+// logs. The lines written through the standard log package, as by the
+// libraries, are logged at info level.
+func Init(name string, lv Level) {
+	mu.Lock()
+	service = name
+	mu.Unlock()
+	SetLevel(lv)
+
+	log.SetFlags(0)
+	log.SetOutput(stdWriter{})
+}
+
+// SetLevel sets the level of the logs, it can change while logging.
+func SetLevel(lv Level) {
+	atomic.StoreInt32(&level, int32(lv))
+}
+
+// SetOutput sets the destination of the logs, stderr by default.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// With returns a logger carrying the given fields.
+func With(fields Fields) *Logger {
+	return root.With(fields)
+}
+
+// With returns a logger carrying the fields of l along with the given ones.
+func (l *Logger) With(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		merged[k] = v
+	}
+	return &Logger{fields: merged}
+}
+
+type ctxKey struct{}
+
+// NewContext returns a context carrying the logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger carried by the context, or a logger without fields.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		return l
+	}
+	return root
+}
+
+// Debugf logs a debug line.
+func (l *Logger) Debugf(format string, args ...interface{}) { l.logf(Debug, format, args...) }
+
+// Infof logs an info line.
+func (l *Logger) Infof(format string, args ...interface{}) { l.logf(Info, format, args...) }
+
+// Warnf logs a warning line.
+func (l *Logger) Warnf(format string, args ...interface{}) { l.logf(Warn, format, args...) }
+
+// Errorf logs an error line.
+func (l *Logger) Errorf(format string, args ...interface{}) { l.logf(Error, format, args...) }
+
+// Fatalf logs a fatal line and exits.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.logf(Fatal, format, args...)
+	os.Exit(1)
+}
+
+// Debugf logs a debug line without fields.
+func Debugf(format string, args ...interface{}) { root.logf(Debug, format, args...) }
+
+// Infof logs an info line without fields.
+func Infof(format string, args ...interface{}) { root.logf(Info, format, args...) }
+
+// Warnf logs a warning line without fields.
+func Warnf(format string, args ...interface{}) { root.logf(Warn, format, args...) }
+
+// Errorf logs an error line without fields.
+func Errorf(format string, args ...interface{}) { root.logf(Error, format, args...) }
+
+// Fatalf logs a fatal line without fields and exits.
+func Fatalf(format string, args ...interface{}) {
+	root.logf(Fatal, format, args...)
+	os.Exit(1)
+}
+
+func (l *Logger) logf(lv Level, format string, args ...interface{}) {
+	if int32(lv) < atomic.LoadInt32(&level) {
+		return
+	}
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	l.write(lv, strings.TrimRight(msg, "\n"))
+}
+
+func (l *Logger) write(lv Level, msg string) {
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	writeValue(&b, time.Now().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	writeValue(&b, lv.String())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if service != "" {
+		b.WriteString(`,"service":`)
+		writeValue(&b, service)
+	}
+	b.WriteString(`,"msg":`)
+	writeValue(&b, msg)
+
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteByte(',')
+		writeValue(&b, k)
+		b.WriteByte(':')
+		writeValue(&b, l.fields[k])
+	}
+	b.WriteString("}\n")
+
+	out.Write(b.Bytes())
+}
+
+func writeValue(b *bytes.Buffer, v interface{}) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		js, _ = json.Marshal(fmt.Sprint(v))
+	}
+	b.Write(js)
+}
+
+// stdWriter turns the lines of the standard log package into info lines.
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	root.logf(Info, "%s", p)
+	return len(p), nil
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	Init("translator", Info)
+	defer log.SetOutput(os.Stderr)
+
+	l := With(Fields{"stream": "storyblok", "pair": "it-en"}).With(Fields{"story_id": 42, "error": errors.New("boom")})
+	l.Debugf("not logged")
+	l.Warnf("translating message ID %s", "1-0")
+	log.Println("from a library")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", lines)
+	}
+
+	var line map[string]interface{}
+	err := json.Unmarshal([]byte(lines[0]), &line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"level":    "warn",
+		"service":  "translator",
+		"msg":      "translating message ID 1-0",
+		"stream":   "storyblok",
+		"pair":     "it-en",
+		"story_id": float64(42),
+		"error":    "boom",
+	}
+	for k, v := range expected {
+		if line[k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, line[k])
+		}
+	}
+
+	err = json.Unmarshal([]byte(lines[1]), &line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line["msg"] != "from a library" || line["level"] != "info" {
+		t.Errorf("expected the standard log line at info level, got %v", line)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	lv, err := ParseLevel("DEBUG")
+	if err != nil || lv != Debug {
+		t.Errorf("expected debug, got %s %v", lv, err)
+	}
+	_, err = ParseLevel("verbose")
+	if err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
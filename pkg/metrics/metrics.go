@@ -0,0 +1,108 @@
+// Package metrics defines the Prometheus metrics of the pipeline.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "polygo"
+
+var (
+	// StoriesEnqueued counts the stories put on the stream to be translated, by source
+	// (api, storyblok, backfill, job, review).
+	StoriesEnqueued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stories_enqueued_total",
+		Help:      "Stories put on the stream to be translated.",
+	}, []string{"source"})
+
+	// MessagesRead counts the messages read by the consumer groups.
+	MessagesRead = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_messages_read_total",
+		Help:      "Messages read from the streams.",
+	}, []string{"stream", "group"})
+
+	// MessagesAcked counts the messages acknowledged by the consumer groups.
+	MessagesAcked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_messages_acked_total",
+		Help:      "Messages acknowledged on the streams.",
+	}, []string{"stream", "group"})
+
+	// TranslationDuration observes the latency of the calls to the translation backends.
+	TranslationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "translation_duration_seconds",
+		Help:      "Latency of the calls to the translation backends.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"backend", "source", "target"})
+
+	// TranslationCharacters counts the characters sent to the translation backends.
+	TranslationCharacters = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "translation_characters_total",
+		Help:      "Characters sent to the translation backends.",
+	}, []string{"backend", "source", "target"})
+
+	// TranslationErrors counts the failed calls to the translation backends.
+	TranslationErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "translation_errors_total",
+		Help:      "Failed calls to the translation backends.",
+	}, []string{"backend", "source", "target"})
+
+	// TranslationQuality observes the similarity of the fields translated back
+	// into the source language with their source text.
+	TranslationQuality = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "translation_quality_score",
+		Help:      "Similarity of the fields translated back with their source text.",
+		Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+	}, []string{"source", "target"})
+
+	// BudgetExhausted is 1 while the budget of the backend translating into the
+	// target language is used up, 0 otherwise.
+	BudgetExhausted = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "translation_budget_exhausted",
+		Help:      "Whether the budget of the translation backend is used up.",
+	}, []string{"backend", "target"})
+
+	// StoryblokRequests counts the requests to the Storyblok APIs by host, method and status code.
+	StoryblokRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storyblok_requests_total",
+		Help:      "Requests to the Storyblok APIs.",
+	}, []string{"host", "method", "code"})
+)
+
+// Handler serves the metrics of the default registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// InstrumentStoryblok wraps the transport counting the requests by status code.
+// Requests failing before a response are counted with code "error".
+func InstrumentStoryblok(next http.RoundTripper) http.RoundTripper {
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		res, err := next.RoundTrip(req)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(res.StatusCode)
+		}
+		StoryblokRequests.WithLabelValues(req.URL.Host, req.Method, code).Inc()
+		return res, err
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt(req)
+}
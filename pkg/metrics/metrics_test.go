@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentStoryblok(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	c := &http.Client{Transport: InstrumentStoryblok(http.DefaultTransport)}
+	for i := 0; i < 2; i++ {
+		res, err := c.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	u, _ := url.Parse(ts.URL)
+	got := testutil.ToFloat64(StoryblokRequests.WithLabelValues(u.Host, "GET", "429"))
+	if got != 2 {
+		t.Errorf("expected 2 requests counted, got %v", got)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	for _, tc := range []struct {
+		in   interface{}
+		want float64
+		ok   bool
+	}{
+		{int64(3), 3, true},
+		{"7", 7, true},
+		{nil, 0, false},
+	} {
+		got, ok := number(tc.in)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("number(%v) = %v, %v; want %v, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/go-redis/redis"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kind84/polygo/pkg/health"
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+var (
+	streamLengthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "stream", "length"),
+		"Number of messages on the stream.",
+		[]string{"stream"}, nil,
+	)
+	pendingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "stream", "pending_messages"),
+		"Messages delivered to the group and not acknowledged yet.",
+		[]string{"stream", "group"}, nil,
+	)
+	lagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "stream", "consumer_lag"),
+		"Messages on the stream not delivered to the group yet (redis 7 and later).",
+		[]string{"stream", "group"}, nil,
+	)
+)
+
+// StreamCollector reads length, pending list size and lag of the consumer groups
+// on redis at each scrape.
+type StreamCollector struct {
+	rdb    *redis.Client
+	groups *health.GroupList
+}
+
+// NewStreamCollector returns a collector of the current consumer groups of the list.
+func NewStreamCollector(rdb *redis.Client, groups *health.GroupList) *StreamCollector {
+	return &StreamCollector{rdb: rdb, groups: groups}
+}
+
+// Describe implements prometheus.Collector.
+func (c *StreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- streamLengthDesc
+	ch <- pendingDesc
+	ch <- lagDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *StreamCollector) Collect(ch chan<- prometheus.Metric) {
+	byStream := make(map[string][]string)
+	var streams []string
+	for _, g := range c.groups.Get() {
+		if _, ok := byStream[g.Stream]; !ok {
+			streams = append(streams, g.Stream)
+		}
+		byStream[g.Stream] = append(byStream[g.Stream], g.Group)
+	}
+
+	for _, stream := range streams {
+		n, err := c.rdb.XLen(stream).Result()
+		if err != nil {
+			logging.Errorf("%s", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(streamLengthDesc, prometheus.GaugeValue, float64(n), stream)
+
+		infos, err := groupInfos(c.rdb, stream)
+		if err != nil {
+			logging.Errorf("%s", err)
+			continue
+		}
+		for _, group := range byStream[stream] {
+			info, ok := infos[group]
+			if !ok {
+				continue
+			}
+			if v, ok := number(info["pending"]); ok {
+				ch <- prometheus.MustNewConstMetric(pendingDesc, prometheus.GaugeValue, v, stream, group)
+			}
+			if v, ok := number(info["lag"]); ok {
+				ch <- prometheus.MustNewConstMetric(lagDesc, prometheus.GaugeValue, v, stream, group)
+			}
+		}
+	}
+}
+
+// groupInfos returns the fields of XINFO GROUPS by group name.
+func groupInfos(rdb *redis.Client, stream string) (map[string]map[string]interface{}, error) {
+	res, err := rdb.Do("XINFO", "GROUPS", stream).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]map[string]interface{})
+	groups, _ := res.([]interface{})
+	for _, g := range groups {
+		fields, _ := g.([]interface{})
+		info := make(map[string]interface{}, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if k, ok := fields[i].(string); ok {
+				info[k] = fields[i+1]
+			}
+		}
+		if name, ok := info["name"].(string); ok {
+			infos[name] = info
+		}
+	}
+	return infos, nil
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
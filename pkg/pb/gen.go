@@ -0,0 +1,4 @@
+// Package pb holds the gRPC services of polygo and their messages.
+package pb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. polygo.proto
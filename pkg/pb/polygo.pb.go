@@ -0,0 +1,740 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: polygo.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Story is a Storyblok story, encoded as JSON since its content depends on the component.
+type Story struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Json                 []byte   `protobuf:"bytes,2,opt,name=json,proto3" json:"json,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Story) Reset()         { *m = Story{} }
+func (m *Story) String() string { return proto.CompactTextString(m) }
+func (*Story) ProtoMessage()    {}
+func (*Story) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{0}
+}
+
+func (m *Story) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Story.Unmarshal(m, b)
+}
+func (m *Story) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Story.Marshal(b, m, deterministic)
+}
+func (m *Story) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Story.Merge(m, src)
+}
+func (m *Story) XXX_Size() int {
+	return xxx_messageInfo_Story.Size(m)
+}
+func (m *Story) XXX_DiscardUnknown() {
+	xxx_messageInfo_Story.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Story proto.InternalMessageInfo
+
+func (m *Story) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Story) GetJson() []byte {
+	if m != nil {
+		return m.Json
+	}
+	return nil
+}
+
+type NewStoriesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NewStoriesRequest) Reset()         { *m = NewStoriesRequest{} }
+func (m *NewStoriesRequest) String() string { return proto.CompactTextString(m) }
+func (*NewStoriesRequest) ProtoMessage()    {}
+func (*NewStoriesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{1}
+}
+
+func (m *NewStoriesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NewStoriesRequest.Unmarshal(m, b)
+}
+func (m *NewStoriesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NewStoriesRequest.Marshal(b, m, deterministic)
+}
+func (m *NewStoriesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NewStoriesRequest.Merge(m, src)
+}
+func (m *NewStoriesRequest) XXX_Size() int {
+	return xxx_messageInfo_NewStoriesRequest.Size(m)
+}
+func (m *NewStoriesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NewStoriesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NewStoriesRequest proto.InternalMessageInfo
+
+type NewStoriesReply struct {
+	Stories              []*Story `protobuf:"bytes,1,rep,name=stories,proto3" json:"stories,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NewStoriesReply) Reset()         { *m = NewStoriesReply{} }
+func (m *NewStoriesReply) String() string { return proto.CompactTextString(m) }
+func (*NewStoriesReply) ProtoMessage()    {}
+func (*NewStoriesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{2}
+}
+
+func (m *NewStoriesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NewStoriesReply.Unmarshal(m, b)
+}
+func (m *NewStoriesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NewStoriesReply.Marshal(b, m, deterministic)
+}
+func (m *NewStoriesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NewStoriesReply.Merge(m, src)
+}
+func (m *NewStoriesReply) XXX_Size() int {
+	return xxx_messageInfo_NewStoriesReply.Size(m)
+}
+func (m *NewStoriesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_NewStoriesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NewStoriesReply proto.InternalMessageInfo
+
+func (m *NewStoriesReply) GetStories() []*Story {
+	if m != nil {
+		return m.Stories
+	}
+	return nil
+}
+
+type GetStoryRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStoryRequest) Reset()         { *m = GetStoryRequest{} }
+func (m *GetStoryRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStoryRequest) ProtoMessage()    {}
+func (*GetStoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{3}
+}
+
+func (m *GetStoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStoryRequest.Unmarshal(m, b)
+}
+func (m *GetStoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStoryRequest.Marshal(b, m, deterministic)
+}
+func (m *GetStoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStoryRequest.Merge(m, src)
+}
+func (m *GetStoryRequest) XXX_Size() int {
+	return xxx_messageInfo_GetStoryRequest.Size(m)
+}
+func (m *GetStoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStoryRequest proto.InternalMessageInfo
+
+func (m *GetStoryRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type SaveRequest struct {
+	Story *Story `protobuf:"bytes,1,opt,name=story,proto3" json:"story,omitempty"`
+	// language code of the translation
+	Lang string `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	// job tracking the translation, if any
+	Job                  string   `protobuf:"bytes,3,opt,name=job,proto3" json:"job,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SaveRequest) Reset()         { *m = SaveRequest{} }
+func (m *SaveRequest) String() string { return proto.CompactTextString(m) }
+func (*SaveRequest) ProtoMessage()    {}
+func (*SaveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{4}
+}
+
+func (m *SaveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SaveRequest.Unmarshal(m, b)
+}
+func (m *SaveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SaveRequest.Marshal(b, m, deterministic)
+}
+func (m *SaveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SaveRequest.Merge(m, src)
+}
+func (m *SaveRequest) XXX_Size() int {
+	return xxx_messageInfo_SaveRequest.Size(m)
+}
+func (m *SaveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SaveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SaveRequest proto.InternalMessageInfo
+
+func (m *SaveRequest) GetStory() *Story {
+	if m != nil {
+		return m.Story
+	}
+	return nil
+}
+
+func (m *SaveRequest) GetLang() string {
+	if m != nil {
+		return m.Lang
+	}
+	return ""
+}
+
+func (m *SaveRequest) GetJob() string {
+	if m != nil {
+		return m.Job
+	}
+	return ""
+}
+
+type SaveReply struct {
+	// state of the translation: saved or review
+	State                string   `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SaveReply) Reset()         { *m = SaveReply{} }
+func (m *SaveReply) String() string { return proto.CompactTextString(m) }
+func (*SaveReply) ProtoMessage()    {}
+func (*SaveReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{5}
+}
+
+func (m *SaveReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SaveReply.Unmarshal(m, b)
+}
+func (m *SaveReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SaveReply.Marshal(b, m, deterministic)
+}
+func (m *SaveReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SaveReply.Merge(m, src)
+}
+func (m *SaveReply) XXX_Size() int {
+	return xxx_messageInfo_SaveReply.Size(m)
+}
+func (m *SaveReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SaveReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SaveReply proto.InternalMessageInfo
+
+func (m *SaveReply) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+type TranslateRequest struct {
+	Story *Story `protobuf:"bytes,1,opt,name=story,proto3" json:"story,omitempty"`
+	// language code of the story, detected when empty
+	Source               string   `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Target               string   `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TranslateRequest) Reset()         { *m = TranslateRequest{} }
+func (m *TranslateRequest) String() string { return proto.CompactTextString(m) }
+func (*TranslateRequest) ProtoMessage()    {}
+func (*TranslateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{6}
+}
+
+func (m *TranslateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TranslateRequest.Unmarshal(m, b)
+}
+func (m *TranslateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TranslateRequest.Marshal(b, m, deterministic)
+}
+func (m *TranslateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TranslateRequest.Merge(m, src)
+}
+func (m *TranslateRequest) XXX_Size() int {
+	return xxx_messageInfo_TranslateRequest.Size(m)
+}
+func (m *TranslateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TranslateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TranslateRequest proto.InternalMessageInfo
+
+func (m *TranslateRequest) GetStory() *Story {
+	if m != nil {
+		return m.Story
+	}
+	return nil
+}
+
+func (m *TranslateRequest) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *TranslateRequest) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+type TranslateReply struct {
+	Translation *Story `protobuf:"bytes,1,opt,name=translation,proto3" json:"translation,omitempty"`
+	Target      string `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	// error translating the story as formatted by its gRPC status, carrying
+	// the status code, set only on streams
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TranslateReply) Reset()         { *m = TranslateReply{} }
+func (m *TranslateReply) String() string { return proto.CompactTextString(m) }
+func (*TranslateReply) ProtoMessage()    {}
+func (*TranslateReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7639fed304d047d4, []int{7}
+}
+
+func (m *TranslateReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TranslateReply.Unmarshal(m, b)
+}
+func (m *TranslateReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TranslateReply.Marshal(b, m, deterministic)
+}
+func (m *TranslateReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TranslateReply.Merge(m, src)
+}
+func (m *TranslateReply) XXX_Size() int {
+	return xxx_messageInfo_TranslateReply.Size(m)
+}
+func (m *TranslateReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_TranslateReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TranslateReply proto.InternalMessageInfo
+
+func (m *TranslateReply) GetTranslation() *Story {
+	if m != nil {
+		return m.Translation
+	}
+	return nil
+}
+
+func (m *TranslateReply) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *TranslateReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Story)(nil), "polygo.Story")
+	proto.RegisterType((*NewStoriesRequest)(nil), "polygo.NewStoriesRequest")
+	proto.RegisterType((*NewStoriesReply)(nil), "polygo.NewStoriesReply")
+	proto.RegisterType((*GetStoryRequest)(nil), "polygo.GetStoryRequest")
+	proto.RegisterType((*SaveRequest)(nil), "polygo.SaveRequest")
+	proto.RegisterType((*SaveReply)(nil), "polygo.SaveReply")
+	proto.RegisterType((*TranslateRequest)(nil), "polygo.TranslateRequest")
+	proto.RegisterType((*TranslateReply)(nil), "polygo.TranslateReply")
+}
+
+func init() { proto.RegisterFile("polygo.proto", fileDescriptor_7639fed304d047d4) }
+
+var fileDescriptor_7639fed304d047d4 = []byte{
+	// 412 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0xcd, 0xce, 0x93, 0x40,
+	0x14, 0xcd, 0xc0, 0xd7, 0x2a, 0xb7, 0xb5, 0x3f, 0x53, 0x53, 0x91, 0x55, 0x3b, 0x9a, 0x48, 0x62,
+	0x02, 0x06, 0x5d, 0x18, 0x8d, 0x89, 0xe9, 0xa6, 0x3b, 0x17, 0xd4, 0x85, 0x71, 0x07, 0xed, 0x04,
+	0x69, 0x29, 0x83, 0xc3, 0x54, 0xc3, 0x7b, 0xf8, 0x22, 0xbe, 0xe1, 0x97, 0x99, 0x81, 0x96, 0xfe,
+	0x6c, 0xba, 0xbb, 0xe7, 0xdc, 0xcb, 0x39, 0x07, 0x4e, 0x80, 0x7e, 0xc1, 0xb2, 0x2a, 0x61, 0x5e,
+	0xc1, 0x99, 0x60, 0xb8, 0xab, 0x11, 0x79, 0x0b, 0x9d, 0x95, 0x60, 0xbc, 0xc2, 0x03, 0x30, 0xd2,
+	0x8d, 0x8d, 0x66, 0xc8, 0x35, 0x43, 0x23, 0xdd, 0x60, 0x0c, 0x0f, 0xdb, 0x92, 0xe5, 0xb6, 0x31,
+	0x43, 0x6e, 0x3f, 0x54, 0x33, 0x99, 0xc0, 0xf8, 0x1b, 0xfd, 0x2b, 0xef, 0x53, 0x5a, 0x86, 0xf4,
+	0xf7, 0x81, 0x96, 0x82, 0x7c, 0x82, 0x61, 0x9b, 0x2c, 0xb2, 0x0a, 0xbf, 0x81, 0x27, 0xa5, 0xc6,
+	0x36, 0x9a, 0x99, 0x6e, 0x2f, 0x78, 0xe6, 0xd5, 0xe6, 0xca, 0x2b, 0x6c, 0xb6, 0x64, 0x0e, 0xc3,
+	0x25, 0x15, 0x9a, 0xd4, 0x72, 0x97, 0x39, 0xc8, 0x0f, 0xe8, 0xad, 0xa2, 0x3f, 0xb4, 0x59, 0xbf,
+	0x82, 0x8e, 0x7c, 0xb8, 0x52, 0x17, 0x57, 0xc2, 0x7a, 0x27, 0xb3, 0x67, 0x51, 0x9e, 0xa8, 0xec,
+	0x56, 0xa8, 0x66, 0x3c, 0x02, 0x73, 0xcb, 0x62, 0xdb, 0x54, 0x94, 0x1c, 0xc9, 0x1c, 0x2c, 0xad,
+	0x2c, 0x23, 0x3f, 0x97, 0xba, 0x91, 0xa0, 0x4a, 0xd7, 0x0a, 0x35, 0x20, 0x09, 0x8c, 0xbe, 0xf3,
+	0x28, 0x2f, 0xb3, 0x48, 0xdc, 0x97, 0x60, 0x0a, 0xdd, 0x92, 0x1d, 0xf8, 0x9a, 0xd6, 0x19, 0x6a,
+	0x24, 0x79, 0x11, 0xf1, 0x84, 0x8a, 0x3a, 0x48, 0x8d, 0x08, 0x83, 0x41, 0xcb, 0x48, 0x06, 0xf2,
+	0xa1, 0x27, 0x6a, 0x26, 0x65, 0xf9, 0x6d, 0xb3, 0xf6, 0x45, 0x4b, 0xda, 0x68, 0x4b, 0xcb, 0x37,
+	0xa3, 0x9c, 0x33, 0x5e, 0x3b, 0x6a, 0x10, 0xfc, 0x47, 0x60, 0x29, 0x91, 0x45, 0xc6, 0x76, 0xf8,
+	0x2b, 0xc0, 0xa9, 0x43, 0xfc, 0xb2, 0x71, 0xb9, 0x2a, 0xdb, 0x79, 0x71, 0x6b, 0x25, 0xe3, 0x06,
+	0xf0, 0xb4, 0x69, 0x12, 0x1f, 0x8f, 0x2e, 0xba, 0x75, 0xce, 0xe3, 0x63, 0x0f, 0x1e, 0x64, 0x01,
+	0x78, 0x72, 0xa4, 0x4f, 0x45, 0x3b, 0xe3, 0x73, 0xb2, 0xc8, 0xaa, 0xe0, 0x1f, 0x02, 0x68, 0xbe,
+	0x12, 0xe3, 0xf8, 0x0b, 0x58, 0x0d, 0xa2, 0xd8, 0x6e, 0xce, 0x2f, 0xfb, 0x72, 0xa6, 0x37, 0x36,
+	0x32, 0xf1, 0x12, 0x86, 0x47, 0x66, 0x25, 0x38, 0x8d, 0xf6, 0xf7, 0x8b, 0xb8, 0xe8, 0x1d, 0x5a,
+	0xbc, 0xfe, 0x49, 0x92, 0x54, 0xfc, 0x3a, 0xc4, 0xde, 0x9a, 0xed, 0xfd, 0x5d, 0x9a, 0x6f, 0x3e,
+	0x7e, 0xf0, 0xf5, 0xb9, 0x5f, 0xec, 0x12, 0xbf, 0x88, 0x3f, 0x17, 0x71, 0xdc, 0x55, 0xff, 0xdd,
+	0xfb, 0xc7, 0x01, 0x00, 0x8c, 0x76, 0x33, 0x38, 0x87, 0x03, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// StoryBlokClient is the client API for StoryBlok service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type StoryBlokClient interface {
+	// NewStories fetches the stories published since the last run and enqueues them to be translated.
+	NewStories(ctx context.Context, in *NewStoriesRequest, opts ...grpc.CallOption) (*NewStoriesReply, error)
+	// GetStory returns the current version of a story.
+	GetStory(ctx context.Context, in *GetStoryRequest, opts ...grpc.CallOption) (*Story, error)
+	// Save saves the translation of a story, or holds it for review when enabled.
+	Save(ctx context.Context, in *SaveRequest, opts ...grpc.CallOption) (*SaveReply, error)
+}
+
+type storyBlokClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStoryBlokClient(cc *grpc.ClientConn) StoryBlokClient {
+	return &storyBlokClient{cc}
+}
+
+func (c *storyBlokClient) NewStories(ctx context.Context, in *NewStoriesRequest, opts ...grpc.CallOption) (*NewStoriesReply, error) {
+	out := new(NewStoriesReply)
+	err := c.cc.Invoke(ctx, "/polygo.StoryBlok/NewStories", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storyBlokClient) GetStory(ctx context.Context, in *GetStoryRequest, opts ...grpc.CallOption) (*Story, error) {
+	out := new(Story)
+	err := c.cc.Invoke(ctx, "/polygo.StoryBlok/GetStory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storyBlokClient) Save(ctx context.Context, in *SaveRequest, opts ...grpc.CallOption) (*SaveReply, error) {
+	out := new(SaveReply)
+	err := c.cc.Invoke(ctx, "/polygo.StoryBlok/Save", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StoryBlokServer is the server API for StoryBlok service.
+type StoryBlokServer interface {
+	// NewStories fetches the stories published since the last run and enqueues them to be translated.
+	NewStories(context.Context, *NewStoriesRequest) (*NewStoriesReply, error)
+	// GetStory returns the current version of a story.
+	GetStory(context.Context, *GetStoryRequest) (*Story, error)
+	// Save saves the translation of a story, or holds it for review when enabled.
+	Save(context.Context, *SaveRequest) (*SaveReply, error)
+}
+
+// UnimplementedStoryBlokServer can be embedded to have forward compatible implementations.
+type UnimplementedStoryBlokServer struct {
+}
+
+func (*UnimplementedStoryBlokServer) NewStories(ctx context.Context, req *NewStoriesRequest) (*NewStoriesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewStories not implemented")
+}
+func (*UnimplementedStoryBlokServer) GetStory(ctx context.Context, req *GetStoryRequest) (*Story, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStory not implemented")
+}
+func (*UnimplementedStoryBlokServer) Save(ctx context.Context, req *SaveRequest) (*SaveReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Save not implemented")
+}
+
+func RegisterStoryBlokServer(s *grpc.Server, srv StoryBlokServer) {
+	s.RegisterService(&_StoryBlok_serviceDesc, srv)
+}
+
+func _StoryBlok_NewStories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewStoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StoryBlokServer).NewStories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/polygo.StoryBlok/NewStories",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StoryBlokServer).NewStories(ctx, req.(*NewStoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StoryBlok_GetStory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StoryBlokServer).GetStory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/polygo.StoryBlok/GetStory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StoryBlokServer).GetStory(ctx, req.(*GetStoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StoryBlok_Save_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StoryBlokServer).Save(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/polygo.StoryBlok/Save",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StoryBlokServer).Save(ctx, req.(*SaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _StoryBlok_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "polygo.StoryBlok",
+	HandlerType: (*StoryBlokServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NewStories",
+			Handler:    _StoryBlok_NewStories_Handler,
+		},
+		{
+			MethodName: "GetStory",
+			Handler:    _StoryBlok_GetStory_Handler,
+		},
+		{
+			MethodName: "Save",
+			Handler:    _StoryBlok_Save_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "polygo.proto",
+}
+
+// TranslatorClient is the client API for Translator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TranslatorClient interface {
+	// Translate translates a story into the target language.
+	Translate(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*TranslateReply, error)
+	// TranslateStream translates each story received, replying as soon as each
+	// translation is ready. Replies are not ordered.
+	TranslateStream(ctx context.Context, opts ...grpc.CallOption) (Translator_TranslateStreamClient, error)
+}
+
+type translatorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTranslatorClient(cc *grpc.ClientConn) TranslatorClient {
+	return &translatorClient{cc}
+}
+
+func (c *translatorClient) Translate(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*TranslateReply, error) {
+	out := new(TranslateReply)
+	err := c.cc.Invoke(ctx, "/polygo.Translator/Translate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *translatorClient) TranslateStream(ctx context.Context, opts ...grpc.CallOption) (Translator_TranslateStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Translator_serviceDesc.Streams[0], "/polygo.Translator/TranslateStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &translatorTranslateStreamClient{stream}
+	return x, nil
+}
+
+type Translator_TranslateStreamClient interface {
+	Send(*TranslateRequest) error
+	Recv() (*TranslateReply, error)
+	grpc.ClientStream
+}
+
+type translatorTranslateStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *translatorTranslateStreamClient) Send(m *TranslateRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *translatorTranslateStreamClient) Recv() (*TranslateReply, error) {
+	m := new(TranslateReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TranslatorServer is the server API for Translator service.
+type TranslatorServer interface {
+	// Translate translates a story into the target language.
+	Translate(context.Context, *TranslateRequest) (*TranslateReply, error)
+	// TranslateStream translates each story received, replying as soon as each
+	// translation is ready. Replies are not ordered.
+	TranslateStream(Translator_TranslateStreamServer) error
+}
+
+// UnimplementedTranslatorServer can be embedded to have forward compatible implementations.
+type UnimplementedTranslatorServer struct {
+}
+
+func (*UnimplementedTranslatorServer) Translate(ctx context.Context, req *TranslateRequest) (*TranslateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Translate not implemented")
+}
+func (*UnimplementedTranslatorServer) TranslateStream(srv Translator_TranslateStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method TranslateStream not implemented")
+}
+
+func RegisterTranslatorServer(s *grpc.Server, srv TranslatorServer) {
+	s.RegisterService(&_Translator_serviceDesc, srv)
+}
+
+func _Translator_Translate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslatorServer).Translate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/polygo.Translator/Translate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslatorServer).Translate(ctx, req.(*TranslateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Translator_TranslateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TranslatorServer).TranslateStream(&translatorTranslateStreamServer{stream})
+}
+
+type Translator_TranslateStreamServer interface {
+	Send(*TranslateReply) error
+	Recv() (*TranslateRequest, error)
+	grpc.ServerStream
+}
+
+type translatorTranslateStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *translatorTranslateStreamServer) Send(m *TranslateReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *translatorTranslateStreamServer) Recv() (*TranslateRequest, error) {
+	m := new(TranslateRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Translator_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "polygo.Translator",
+	HandlerType: (*TranslatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Translate",
+			Handler:    _Translator_Translate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TranslateStream",
+			Handler:       _Translator_TranslateStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "polygo.proto",
+}
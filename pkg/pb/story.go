@@ -0,0 +1,26 @@
+package pb
+
+import (
+	"encoding/json"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// NewStory encodes the story into its message.
+func NewStory(s types.Story) (*Story, error) {
+	js, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Story{Id: int64(s.ID), Json: js}, nil
+}
+
+// Decode decodes the story carried by the message.
+func (m *Story) Decode() (types.Story, error) {
+	var s types.Story
+	if m == nil {
+		return s, nil
+	}
+	err := json.Unmarshal(m.Json, &s)
+	return s, err
+}
@@ -0,0 +1,37 @@
+package pb
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestStoryRoundTrip(t *testing.T) {
+	s := types.Story{ID: 42, Name: "Tiramisù"}
+	s.Content.Component = "recipe"
+	s.Content.Title = "Tiramisù"
+
+	m, err := NewStory(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := proto.Marshal(&TranslateRequest{Story: m, Target: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var req TranslateRequest
+	err = proto.Unmarshal(b, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := req.GetStory().Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 42 || got.Content.Title != "Tiramisù" || got.Content.Component != "recipe" {
+		t.Errorf("unexpected story %+v", got)
+	}
+}
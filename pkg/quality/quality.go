@@ -0,0 +1,117 @@
+// Package quality scores machine translations by translating them back into
+// the source language and comparing each field with the source text.
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// Field is the field of the stream messages carrying the report of the
+// translated story.
+const Field = "quality"
+
+// FieldScore is the similarity of a field back-translated with its source text,
+// from 0 (nothing in common) to 1 (same words).
+type FieldScore struct {
+	Path            string  `json:"path"`
+	Score           float64 `json:"score"`
+	BackTranslation string  `json:"back_translation"`
+}
+
+// Report holds the scores of the fields of a translated story.
+type Report struct {
+	Threshold float64      `json:"threshold"`
+	Fields    []FieldScore `json:"fields"`
+}
+
+// Compare scores the fields of the story translated back into the source
+// language against the source story. Empty source fields are not scored.
+func Compare(source, back types.Story, threshold float64) *Report {
+	backs := make(map[string]string)
+	for _, f := range back.TextFields() {
+		backs[f.Path] = f.Value
+	}
+
+	r := &Report{Threshold: threshold, Fields: []FieldScore{}}
+	for _, f := range source.TextFields() {
+		if f.Value == "" {
+			continue
+		}
+		r.Fields = append(r.Fields, FieldScore{
+			Path:            f.Path,
+			Score:           Similarity(f.Value, backs[f.Path]),
+			BackTranslation: backs[f.Path],
+		})
+	}
+	return r
+}
+
+// Low returns the fields scoring below the threshold, none for a nil report.
+func (r *Report) Low() []FieldScore {
+	if r == nil {
+		return nil
+	}
+	var low []FieldScore
+	for _, f := range r.Fields {
+		if f.Score < r.Threshold {
+			low = append(low, f)
+		}
+	}
+	return low
+}
+
+// FromMessage returns the report carried by the stream message, nil if none.
+func FromMessage(values map[string]interface{}) (*Report, error) {
+	js, ok := values[Field].(string)
+	if !ok {
+		return nil, nil
+	}
+	var r Report
+	err := json.Unmarshal([]byte(js), &r)
+	if err != nil {
+		return nil, fmt.Errorf("decoding quality report: %s", err)
+	}
+	return &r, nil
+}
+
+// Tag returns the tag marking on Storyblok the stories with a low confidence
+// translation in the given language.
+func Tag(lang string) string {
+	return "low-confidence-" + lang
+}
+
+// Similarity returns the Dice coefficient of the words of the two texts,
+// regardless of case, punctuation and order.
+func Similarity(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+
+	count := make(map[string]int, len(wa))
+	for _, w := range wa {
+		count[w]++
+	}
+	common := 0
+	for _, w := range wb {
+		if count[w] > 0 {
+			count[w]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wa)+len(wb))
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
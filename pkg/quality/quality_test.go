@@ -0,0 +1,73 @@
+package quality
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		score float64
+	}{
+		{"Cuocere la pasta.", "cuocere la PASTA", 1},
+		{"Cuocere la pasta", "Scolare la pasta", 2.0 / 3},
+		{"la pasta la pasta", "la pasta", 2.0 / 3},
+		{"Cuocere la pasta", "Tagliare il pane", 0},
+		{"", "", 1},
+		{"Pasta", "", 0},
+	}
+	for _, tt := range tests {
+		if s := Similarity(tt.a, tt.b); math.Abs(s-tt.score) > 1e-9 {
+			t.Errorf("Similarity(%q, %q): expected %g, got %g", tt.a, tt.b, tt.score, s)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	var source, back types.Story
+	source.Content.Title = "Pasta al pomodoro"
+	source.Content.Summary = "Un primo piatto veloce"
+	back.Content.Title = "Pasta al pomodoro"
+	back.Content.Summary = "Una prima portata rapida"
+
+	r := Compare(source, back, 0.6)
+	if len(r.Fields) != 2 {
+		t.Fatalf("expected 2 scored fields, got %+v", r.Fields)
+	}
+
+	low := r.Low()
+	if len(low) != 1 || low[0].Path != "summary" {
+		t.Fatalf("expected summary to score low, got %+v", low)
+	}
+	if low[0].BackTranslation != back.Content.Summary {
+		t.Errorf("expected back-translation %q, got %q", back.Content.Summary, low[0].BackTranslation)
+	}
+
+	var nilReport *Report
+	if nilReport.Low() != nil {
+		t.Error("expected no low fields for a nil report")
+	}
+}
+
+func TestFromMessage(t *testing.T) {
+	r, err := FromMessage(map[string]interface{}{"story": "{}"})
+	if r != nil || err != nil {
+		t.Errorf("expected no report, got %v, %v", r, err)
+	}
+
+	r, err = FromMessage(map[string]interface{}{Field: `{"threshold":0.5,"fields":[{"path":"title","score":0.25}]}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Threshold != 0.5 || len(r.Low()) != 1 {
+		t.Errorf("unexpected report %+v", r)
+	}
+
+	_, err = FromMessage(map[string]interface{}{Field: "oops"})
+	if err == nil {
+		t.Error("expected error decoding the report")
+	}
+}
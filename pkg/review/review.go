@@ -0,0 +1,364 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/quality"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// Status of an item in the review queue.
+type Status string
+
+const (
+	Pending  Status = "pending"
+	Rejected Status = "rejected"
+)
+
+const (
+	// ApprovedStream receives the approved translations to be saved on Storyblok.
+	ApprovedStream = "review_approved"
+	// RequeueStream receives the source stories of rejected translations to be translated again.
+	RequeueStream = "storyblok"
+
+	itemsKey     = "review:items"
+	statusPrefix = "review:status:"
+)
+
+var (
+	ErrNotFound   = errors.New("review item not found")
+	ErrNotPending = errors.New("review item is not pending")
+)
+
+// Item is a translation waiting for a human review.
+type Item struct {
+	ID      string `json:"id"`
+	StoryID int    `json:"story_id"`
+	Lang    string `json:"lang"`
+	Status  Status `json:"status"`
+	Note    string `json:"note,omitempty"`
+	Job     string `json:"job,omitempty"`
+	// Force overrides the translation saved already once approved.
+	Force bool `json:"force,omitempty"`
+	// Edited is set once the translation has been edited by a reviewer.
+	Edited bool `json:"edited,omitempty"`
+	// Quality holds the scores of the fields translated back, if checked.
+	Quality   *quality.Report `json:"quality,omitempty"`
+	Source    types.Story     `json:"source"`
+	Target    types.Story     `json:"target"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// FieldPair holds source and target text of the same story field.
+type FieldPair struct {
+	Path   string `json:"path"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Fields returns source and target texts side by side.
+func (i *Item) Fields() []FieldPair {
+	target := make(map[string]string)
+	for _, f := range i.Target.TextFields() {
+		target[f.Path] = f.Value
+	}
+
+	var fp []FieldPair
+	for _, f := range i.Source.TextFields() {
+		fp = append(fp, FieldPair{
+			Path:   f.Path,
+			Source: f.Value,
+			Target: target[f.Path],
+		})
+	}
+	return fp
+}
+
+// Queue is the redis backed queue of translations waiting for review.
+type Queue struct {
+	rdb *redis.Client
+}
+
+// NewQueue returns a new review queue stored on the given redis instance.
+func NewQueue(rdb *redis.Client) *Queue {
+	return &Queue{rdb: rdb}
+}
+
+// ItemID returns the ID of the review item for the given story and language.
+func ItemID(storyID int, lang string) string {
+	return fmt.Sprintf("%d:%s", storyID, lang)
+}
+
+// Push adds a translation, belonging to the given job if any, to the queue
+// along with its quality report, if any. A translation already queued for the
+// same story and language is replaced, keeping the note of the last rejection.
+func (q *Queue) Push(source, target types.Story, lang string, jobID string, force bool, report *quality.Report) (*Item, error) {
+	now := time.Now().UTC()
+	item := &Item{
+		ID:        ItemID(source.ID, lang),
+		StoryID:   source.ID,
+		Lang:      lang,
+		Status:    Pending,
+		Job:       jobID,
+		Force:     force,
+		Quality:   report,
+		Source:    source,
+		Target:    target,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	old, err := q.Get(item.ID)
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+	if old != nil {
+		item.Note = old.Note
+		item.CreatedAt = old.CreatedAt
+	}
+
+	err = q.save(item, old)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// List returns the items with the given status, oldest first.
+func (q *Queue) List(status Status) ([]Item, error) {
+	ids, err := q.rdb.ZRange(statusPrefix+string(status), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []Item{}, nil
+	}
+
+	vals, err := q.rdb.HMGet(itemsKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(vals))
+	for _, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			// item removed in the meantime
+			continue
+		}
+		var item Item
+		err = json.Unmarshal([]byte(s), &item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Get returns the item with the given ID.
+func (q *Queue) Get(id string) (*Item, error) {
+	return decodeItem(q.rdb.HGet(itemsKey, id).Result())
+}
+
+func decodeItem(s string, err error) (*Item, error) {
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var item Item
+	err = json.Unmarshal([]byte(s), &item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Edit replaces the translated story of a pending item.
+func (q *Queue) Edit(id string, target types.Story) (*Item, error) {
+	var edited Item
+	err := q.transition(id, func(pipe redis.Pipeliner, item *Item) error {
+		edited = *item
+		edited.Target = target
+		edited.Target.ID = item.StoryID
+		edited.Edited = true
+		edited.UpdatedAt = time.Now().UTC()
+
+		js, err := json.Marshal(edited)
+		if err != nil {
+			return err
+		}
+		pipe.HSet(itemsKey, id, js)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &edited, nil
+}
+
+// Approve removes a pending item from the queue and forwards its translation
+// to the approved stream to be saved. It returns the ID of the stream message.
+// Edited translations are sent as well to be translated into the languages
+// translated from the item language, overriding the translations made from
+// the translation before the edit.
+func (q *Queue) Approve(id string) (string, error) {
+	var xadd *redis.StringCmd
+	err := q.transition(id, func(pipe redis.Pipeliner, item *Item) error {
+		js, err := json.Marshal(item.Target)
+		if err != nil {
+			return err
+		}
+
+		pipe.HDel(itemsKey, id)
+		pipe.ZRem(statusPrefix+string(item.Status), id)
+		values := map[string]interface{}{
+			"story": js,
+			"lang":  item.Lang,
+		}
+		if item.Job != "" {
+			values[job.Field] = item.Job
+		}
+		if item.Force {
+			values[types.ForceField] = item.Lang
+		}
+		xadd = pipe.XAdd(&redis.XAddArgs{
+			Stream: ApprovedStream,
+			Values: values,
+		})
+
+		if item.Edited {
+			pipe.XAdd(&redis.XAddArgs{
+				Stream: translationStream(item.Lang),
+				Values: map[string]interface{}{
+					"story":               js,
+					types.DownstreamField: item.Lang,
+				},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return xadd.Val(), nil
+}
+
+// Reject marks a pending item as rejected with the given note and sends its
+// source story back to be translated again into the item language only. It
+// returns the ID of the stream message. The note is kept on the item, shown
+// along with the new translation once queued again.
+func (q *Queue) Reject(id, note string) (string, error) {
+	var xadd *redis.StringCmd
+	err := q.transition(id, func(pipe redis.Pipeliner, item *Item) error {
+		rejected := *item
+		rejected.Status = Rejected
+		rejected.Note = note
+		rejected.UpdatedAt = time.Now().UTC()
+
+		js, err := json.Marshal(item.Source)
+		if err != nil {
+			return err
+		}
+		jr, err := json.Marshal(rejected)
+		if err != nil {
+			return err
+		}
+
+		pipe.HSet(itemsKey, id, jr)
+		pipe.ZRem(statusPrefix+string(item.Status), id)
+		pipe.ZAdd(statusPrefix+string(rejected.Status), redis.Z{
+			Score:  float64(rejected.UpdatedAt.Unix()),
+			Member: id,
+		})
+		values := map[string]interface{}{
+			"story":            js,
+			types.TargetsField: item.Lang,
+		}
+		if item.Job != "" {
+			values[job.Field] = item.Job
+		}
+		if item.Force {
+			values[types.ForceField] = item.Lang
+		}
+		xadd = pipe.XAdd(&redis.XAddArgs{
+			Stream: RequeueStream,
+			Values: values,
+		})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	metrics.StoriesEnqueued.WithLabelValues("review").Inc()
+	return xadd.Val(), nil
+}
+
+// attempts of a transition of an item when the items change in the meantime
+const maxTransitionAttempts = 10
+
+// transition runs fn with the pending item with the given ID, fn queueing on
+// the pipeline the writes moving the item to its next state. The items are
+// watched while reading the item until the writes are executed, so that of
+// two concurrent transitions of an item only one succeeds, the other finding
+// the item not pending anymore.
+func (q *Queue) transition(id string, fn func(pipe redis.Pipeliner, item *Item) error) error {
+	for i := 0; i < maxTransitionAttempts; i++ {
+		err := q.rdb.Watch(func(tx *redis.Tx) error {
+			item, err := decodeItem(tx.HGet(itemsKey, id).Result())
+			if err != nil {
+				return err
+			}
+			if item.Status != Pending {
+				return ErrNotPending
+			}
+
+			_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+				return fn(pipe, item)
+			})
+			return err
+		}, itemsKey)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return redis.TxFailedErr
+}
+
+// translationStream returns the stream the translator sends the translations
+// into the language to.
+func translationStream(lang string) string {
+	return "translation_" + lang
+}
+
+// save stores the item and moves it to the index of its status.
+func (q *Queue) save(item, old *Item) error {
+	js, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HSet(itemsKey, item.ID, js)
+		if old != nil && old.Status != item.Status {
+			pipe.ZRem(statusPrefix+string(old.Status), item.ID)
+		}
+		pipe.ZAdd(statusPrefix+string(item.Status), redis.Z{
+			Score:  float64(item.CreatedAt.Unix()),
+			Member: item.ID,
+		})
+		return nil
+	})
+	return err
+}
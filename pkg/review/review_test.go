@@ -0,0 +1,29 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestItemFields(t *testing.T) {
+	var source, target types.Story
+	source.Content.Title = "Torta di mele"
+	target.Content.Title = "Apple pie"
+	source.Content.Steps = []types.Step{{UID: "s1", Content: "Sbucciare le mele"}}
+	target.Content.Steps = []types.Step{{UID: "s1", Content: "Peel the apples"}}
+
+	item := Item{Source: source, Target: target}
+
+	got := make(map[string]FieldPair)
+	for _, f := range item.Fields() {
+		got[f.Path] = f
+	}
+
+	if f := got["title"]; f.Source != "Torta di mele" || f.Target != "Apple pie" {
+		t.Errorf("Error pairing title: got %+v", f)
+	}
+	if f := got["steps.s1.content"]; f.Source != "Sbucciare le mele" || f.Target != "Peel the apples" {
+		t.Errorf("Error pairing step content: got %+v", f)
+	}
+}
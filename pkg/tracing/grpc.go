@@ -0,0 +1,22 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/plugin/grpctrace"
+	"google.golang.org/grpc"
+)
+
+// ServerOptions make a grpc server continue the traces of the requests.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(grpctrace.UnaryServerInterceptor(Tracer())),
+		grpc.StreamInterceptor(grpctrace.StreamServerInterceptor(Tracer())),
+	}
+}
+
+// DialOptions make a grpc client send the trace context along with the requests.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(grpctrace.UnaryClientInterceptor(Tracer())),
+		grpc.WithStreamInterceptor(grpctrace.StreamClientInterceptor(Tracer())),
+	}
+}
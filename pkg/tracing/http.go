@@ -0,0 +1,29 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/plugin/othttp"
+)
+
+// Handler starts a span for each request served by h, named after its method
+// and the route returned by route, a path template such as /jobs/:id keeping
+// the span names few. Requests to the given paths, such as probes and
+// metrics, are not traced.
+func Handler(h http.Handler, route func(req *http.Request) string, skip ...string) http.Handler {
+	return othttp.NewHandler(h, "http",
+		othttp.WithTracer(Tracer()),
+		othttp.WithPropagators(propagators),
+		othttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+			return req.Method + " " + route(req)
+		}),
+		othttp.WithFilter(func(req *http.Request) bool {
+			for _, p := range skip {
+				if req.URL.Path == p {
+					return false
+				}
+			}
+			return true
+		}),
+	)
+}
@@ -0,0 +1,147 @@
+// Package tracing sets up OpenTelemetry and carries the trace context across
+// the redis streams.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/propagation"
+	"go.opentelemetry.io/otel/api/standard"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+// name of the tracer of polygo
+const tracerName = "github.com/kind84/polygo"
+
+// stream messages carry the W3C trace context
+var propagators = propagation.New(
+	propagation.WithInjectors(trace.TraceContext{}),
+	propagation.WithExtractors(trace.TraceContext{}),
+)
+
+// Fields are the names of the stream message fields carrying the trace context.
+var Fields = trace.TraceContext{}.GetAllKeys()
+
+// Init exports the spans of the service over OTLP (gRPC) to the collector at
+// endpoint, sampling the given ratio of the traces started by the service:
+// none when 0, all of them when 1.
+// Tracing is disabled when endpoint is empty. The returned function flushes
+// the spans still buffered.
+func Init(service string, endpoint string, sampleRatio float64) (func(), error) {
+	if endpoint == "" {
+		return func() {}, nil
+	}
+
+	exp, err := otlp.NewExporter(
+		otlp.WithInsecure(),
+		otlp.WithAddress(endpoint),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var sampler sdktrace.Sampler
+	switch {
+	case sampleRatio <= 0:
+		sampler = sdktrace.NeverSample()
+	case sampleRatio >= 1:
+		sampler = sdktrace.AlwaysSample()
+	default:
+		sampler = sdktrace.ProbabilitySampler(sampleRatio)
+	}
+
+	bsp, err := sdktrace.NewBatchSpanProcessor(exp)
+	if err != nil {
+		exp.Stop()
+		return nil, err
+	}
+	tp, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sampler}),
+		sdktrace.WithResource(resource.New(standard.ServiceNameKey.String(service))),
+	)
+	if err != nil {
+		exp.Stop()
+		return nil, err
+	}
+	tp.RegisterSpanProcessor(bsp)
+	global.SetTraceProvider(tp)
+	logging.Infof("Exporting traces to %s", endpoint)
+
+	return func() {
+		// unregistering the processor flushes its queue
+		tp.UnregisterSpanProcessor(bsp)
+		err := exp.Stop()
+		if err != nil {
+			logging.Errorf("%s", err)
+		}
+	}, nil
+}
+
+// Tracer returns the tracer of polygo.
+func Tracer() trace.Tracer {
+	return global.Tracer(tracerName)
+}
+
+// Start starts a span as child of the span in the context, if any.
+func Start(ctx context.Context, name string, attrs ...kv.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error, if any, on the span and ends it.
+func End(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err)
+	}
+	span.End()
+}
+
+// Inject stores the trace context of ctx in the values of a stream message.
+func Inject(ctx context.Context, values map[string]interface{}) {
+	propagation.InjectHTTP(ctx, propagators, valuesSupplier(values))
+}
+
+// Extract returns a context carrying the trace context stored in the values
+// of a stream message, to start the spans following the message.
+func Extract(ctx context.Context, values map[string]interface{}) context.Context {
+	return propagation.ExtractHTTP(ctx, propagators, valuesSupplier(values))
+}
+
+// InjectMap stores the trace context of ctx in a map, to be sent along with a jsonrpc request.
+func InjectMap(ctx context.Context, m map[string]string) {
+	propagation.InjectHTTP(ctx, propagators, mapSupplier(m))
+}
+
+// ExtractMap returns a context carrying the trace context stored in a map.
+func ExtractMap(ctx context.Context, m map[string]string) context.Context {
+	return propagation.ExtractHTTP(ctx, propagators, mapSupplier(m))
+}
+
+type valuesSupplier map[string]interface{}
+
+func (s valuesSupplier) Get(key string) string {
+	v, _ := s[key].(string)
+	return v
+}
+
+func (s valuesSupplier) Set(key string, value string) {
+	s[key] = value
+}
+
+type mapSupplier map[string]string
+
+func (s mapSupplier) Get(key string) string {
+	return s[key]
+}
+
+func (s mapSupplier) Set(key string, value string) {
+	if s != nil {
+		s[key] = value
+	}
+}
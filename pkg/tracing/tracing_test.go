@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestInjectExtract(t *testing.T) {
+	tp, err := sdktrace.NewProvider(sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, span := tp.Tracer("test").Start(context.Background(), "enqueue")
+	defer span.End()
+	sc := span.SpanContext()
+
+	values := map[string]interface{}{"story": "{}"}
+	Inject(ctx, values)
+	if _, ok := values["traceparent"].(string); !ok {
+		t.Fatalf("expected traceparent field, got %v", values)
+	}
+
+	got := trace.RemoteSpanContextFromContext(Extract(context.Background(), values))
+	if got.TraceID != sc.TraceID || got.SpanID != sc.SpanID {
+		t.Errorf("expected span context %v, got %v", sc, got)
+	}
+}
+
+func TestExtractMissing(t *testing.T) {
+	got := trace.RemoteSpanContextFromContext(Extract(context.Background(), map[string]interface{}{}))
+	if got.IsValid() {
+		t.Errorf("expected no span context, got %v", got)
+	}
+}
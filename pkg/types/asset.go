@@ -0,0 +1,91 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// asset shapes found on Storyblok content, unset when the field is missing
+const (
+	assetUnset = iota
+	assetObject
+	assetString
+	assetNull
+)
+
+// Asset is a Storyblok asset field. Older stories store the bare filename in
+// place of the asset object: the asset is marshaled back in the same shape it
+// has been read, so that the image reference is kept unchanged. Assets missing
+// from the content are left out when the content is marshaled.
+type Asset struct {
+	ID        int         `json:"id"`
+	Alt       string      `json:"alt"`
+	Name      string      `json:"name"`
+	Focus     interface{} `json:"focus"`
+	Title     string      `json:"title"`
+	Filename  string      `json:"filename"`
+	Copyright string      `json:"copyright"`
+	FieldType string      `json:"fieldtype"`
+
+	shape int
+	// source is the asset in the default language, set when the translated
+	// asset is saved as an i18n variant.
+	source *Asset
+}
+
+// asset alias to marshal the object without recursion
+type assetObj Asset
+
+// HasText tells whether the asset carries alt text and title to be translated.
+func (a Asset) HasText() bool {
+	return a.shape == assetObject
+}
+
+// SetSource sets the asset in the default language to be saved along with
+// the translated one.
+func (a *Asset) SetSource(source Asset) {
+	a.source = &source
+}
+
+// implement UnmarshalJSON for type Asset
+func (a *Asset) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*a = Asset{shape: assetNull}
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var filename string
+		err := json.Unmarshal(data, &filename)
+		if err != nil {
+			return err
+		}
+		*a = Asset{Filename: filename, shape: assetString}
+		return nil
+	}
+
+	var obj assetObj
+	err := json.Unmarshal(data, &obj)
+	if err != nil {
+		return err
+	}
+	*a = Asset(obj)
+	a.shape = assetObject
+	return nil
+}
+
+// isSet tells whether the asset has been read from the content.
+func (a Asset) isSet() bool {
+	return a.shape != assetUnset
+}
+
+// implement MarshalJSON for type Asset
+func (a Asset) MarshalJSON() ([]byte, error) {
+	switch a.shape {
+	case assetUnset, assetNull:
+		return []byte("null"), nil
+	case assetString:
+		return json.Marshal(a.Filename)
+	}
+	return json.Marshal(assetObj(a))
+}
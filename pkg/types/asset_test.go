@@ -0,0 +1,102 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAssetRoundTrip(t *testing.T) {
+	tests := []string{
+		`"https://a.storyblok.com/f/1/pie.jpg"`,
+		`null`,
+		`{"id":7,"alt":"Torta","name":"","focus":null,"title":"Torta di mele","filename":"https://a.storyblok.com/f/1/pie.jpg","copyright":"","fieldtype":"asset"}`,
+	}
+
+	for _, js := range tests {
+		var a Asset
+		err := json.Unmarshal([]byte(js), &a)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		got, err := json.Marshal(a)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(got) != js {
+			t.Errorf("Error marshaling asset back: got %s, want %s", got, js)
+		}
+	}
+}
+
+func TestRecipeAssetVariant(t *testing.T) {
+	var source Asset
+	err := json.Unmarshal([]byte(`{"alt":"Torta","filename":"pie.jpg","fieldtype":"asset"}`), &source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated := source
+	translated.Alt = "Pie"
+	translated.SetSource(source)
+
+	r := Recipe{Image: translated, Lang: "__i18n__en"}
+	js, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]json.RawMessage
+	err = json.Unmarshal(js, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var img, imgEn Asset
+	json.Unmarshal(out["image"], &img)
+	json.Unmarshal(out["image__i18n__en"], &imgEn)
+	if img.Alt != "Torta" || img.Filename != "pie.jpg" {
+		t.Errorf("Error keeping source image: got %+v", img)
+	}
+	if imgEn.Alt != "Pie" || imgEn.Filename != "pie.jpg" {
+		t.Errorf("Error saving translated image: got %+v", imgEn)
+	}
+}
+
+func TestMissingAssets(t *testing.T) {
+	var story Story
+	err := json.Unmarshal([]byte(`{"id":1,"content":{"_uid":"r1","title":"Torta","steps":[{"_uid":"s1","title":"Impastare"}]}}`), &story)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.Content.Image.HasText() || story.Content.Steps[0].Thumbnail.HasText() {
+		t.Fatal("expected missing image and thumbnail to have no text")
+	}
+
+	// as prepareStory does for a translation
+	story.Content.Lang = "__i18n__en"
+	story.Content.Steps[0].Lang = "__i18n__en"
+	js, err := json.Marshal(story.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var content map[string]json.RawMessage
+	json.Unmarshal(js, &content)
+	for _, k := range []string{"image", "image__i18n__en"} {
+		if _, ok := content[k]; ok {
+			t.Errorf("expected missing image to stay missing, got %s: %s", k, content[k])
+		}
+	}
+	var steps []map[string]json.RawMessage
+	json.Unmarshal(content["steps"], &steps)
+	if len(steps) != 1 {
+		t.Fatalf("expected 1 step, got %s", content["steps"])
+	}
+	for _, k := range []string{"thumbnail", "thumbnail__i18n__en"} {
+		if _, ok := steps[0][k]; ok {
+			t.Errorf("expected missing thumbnail to stay missing, got %s: %s", k, steps[0][k])
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package types
+
+// Datasource is a Storyblok datasource, a list of key/value entries.
+type Datasource struct {
+	ID         int         `json:"id"`
+	Name       string      `json:"name"`
+	Slug       string      `json:"slug"`
+	Dimensions []Dimension `json:"dimensions"`
+}
+
+// Dimension is a variant of the datasource values, one per language.
+type Dimension struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	EntryValue string `json:"entry_value"`
+}
+
+// DatasourceEntry is a single entry of a datasource. Value holds the text in the
+// default dimension, DimensionValue the text in the requested dimension if any.
+type DatasourceEntry struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Value          string `json:"value"`
+	DimensionValue string `json:"dimension_value"`
+	DatasourceID   int    `json:"datasource_id"`
+}
@@ -0,0 +1,16 @@
+package types
+
+// TranslateStoryRequest is the body of a translation request to the server:
+// the story to be translated into each of the target languages is either sent
+// along or fetched from Storyblok by ID.
+type TranslateStoryRequest struct {
+	Story   *Story   `json:"story,omitempty"`
+	StoryID int      `json:"story_id,omitempty"`
+	Source  string   `json:"source,omitempty"`
+	Targets []string `json:"targets"`
+}
+
+// TranslateStoryReply holds the translations of the story by language.
+type TranslateStoryReply struct {
+	Translations map[string]Story `json:"translations"`
+}
@@ -1,8 +1,13 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -34,7 +39,7 @@ type Recipe struct {
 	Cost         string      `json:"cost"`
 	Prep         string      `json:"prep"`
 	Extra        string      `json:"extra"`
-	Image        string      `json:"image"`
+	Image        Asset       `json:"image"`
 	Likes        interface{} `json:"likes"`
 	Steps        []Step      `json:"steps"`
 	Title        string      `json:"title"`
@@ -67,7 +72,7 @@ type Step struct {
 	Title     string `json:"title"`
 	Content   string `json:"content"`
 	Component string `json:"component"`
-	Thumbnail string `json:"thumbnail"`
+	Thumbnail Asset  `json:"thumbnail"`
 	Lang      string `json:"-"`
 }
 
@@ -144,6 +149,17 @@ func marshalJSON(namingStrategy string, that interface{}) ([]byte, error) {
 			outName = fname(n)
 		case "content":
 			outName = fname(n)
+		case "image", "thumbnail":
+			// translated assets are saved as i18n variants along with the source asset
+			outName = n
+			a, ok := v.Field(i).Interface().(Asset)
+			if ok && !a.isSet() {
+				// missing from the content, left missing
+				outName = ""
+			} else if ok && a.source != nil {
+				out[n] = *a.source
+				outName = fname(n)
+			}
 		default:
 			outName = n
 		}
@@ -153,3 +169,175 @@ func marshalJSON(namingStrategy string, that interface{}) ([]byte, error) {
 	}
 	return json.Marshal(out)
 }
+
+// TextField is a single translatable text of a story, addressed by its path
+// inside the story content (e.g. "title", "steps.<uid>.content", "ingredients.0.name").
+type TextField struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// TextFields lists the translatable text fields of the story content.
+func (s Story) TextFields() []TextField {
+	c := s.Content
+	fields := []TextField{
+		{Path: "title", Value: c.Title},
+		{Path: "summary", Value: c.Summary},
+		{Path: "description", Value: c.Description},
+		{Path: "conclusion", Value: c.Conclusion},
+		{Path: "extra", Value: c.Extra},
+	}
+	fields = append(fields, assetFields("image", c.Image)...)
+	for _, stp := range c.Steps {
+		fields = append(fields,
+			TextField{Path: "steps." + stp.UID + ".title", Value: stp.Title},
+			TextField{Path: "steps." + stp.UID + ".content", Value: stp.Content},
+		)
+		fields = append(fields, assetFields("steps."+stp.UID+".thumbnail", stp.Thumbnail)...)
+	}
+	for i, igr := range c.Ingredients.Ingredients {
+		fields = append(fields,
+			TextField{Path: "ingredients." + strconv.Itoa(i) + ".name", Value: igr.Name},
+			TextField{Path: "ingredients." + strconv.Itoa(i) + ".unit", Value: igr.Unit},
+		)
+	}
+	return fields
+}
+
+// SetTextField sets the text of the field at the given path, as listed by TextFields.
+func (s *Story) SetTextField(path, value string) error {
+	c := &s.Content
+	parts := strings.SplitN(path, ".", 3)
+	switch parts[0] {
+	case "title":
+		c.Title = value
+	case "summary":
+		c.Summary = value
+	case "description":
+		c.Description = value
+	case "conclusion":
+		c.Conclusion = value
+	case "extra":
+		c.Extra = value
+	case "image":
+		if len(parts) == 2 && setAssetField(&c.Image, parts[1], value) {
+			return nil
+		}
+		return fmt.Errorf("unknown field %s", path)
+	case "steps":
+		if len(parts) < 3 {
+			return fmt.Errorf("unknown field %s", path)
+		}
+		for i := range c.Steps {
+			stp := &c.Steps[i]
+			if stp.UID != parts[1] {
+				continue
+			}
+			switch field := parts[2]; field {
+			case "title":
+				stp.Title = value
+			case "content":
+				stp.Content = value
+			default:
+				if !strings.HasPrefix(field, "thumbnail.") ||
+					!setAssetField(&stp.Thumbnail, strings.TrimPrefix(field, "thumbnail."), value) {
+					return fmt.Errorf("unknown field %s", path)
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("step %s not found", parts[1])
+	case "ingredients":
+		if len(parts) < 3 {
+			return fmt.Errorf("unknown field %s", path)
+		}
+		i, err := strconv.Atoi(parts[1])
+		if err != nil || i < 0 || i >= len(c.Ingredients.Ingredients) {
+			return fmt.Errorf("ingredient %s not found", parts[1])
+		}
+		switch parts[2] {
+		case "name":
+			c.Ingredients.Ingredients[i].Name = value
+		case "unit":
+			c.Ingredients.Ingredients[i].Unit = value
+		default:
+			return fmt.Errorf("unknown field %s", path)
+		}
+	default:
+		return fmt.Errorf("unknown field %s", path)
+	}
+	return nil
+}
+
+func setAssetField(a *Asset, field, value string) bool {
+	if !a.HasText() {
+		return false
+	}
+	switch field {
+	case "alt":
+		a.Alt = value
+	case "title":
+		a.Title = value
+	default:
+		return false
+	}
+	return true
+}
+
+func assetFields(path string, a Asset) []TextField {
+	if !a.HasText() {
+		return nil
+	}
+	return []TextField{
+		{Path: path + ".alt", Value: a.Alt},
+		{Path: path + ".title", Value: a.Title},
+	}
+}
+
+// Validate checks that the story carries the data needed to be translated and saved.
+func (s Story) Validate() error {
+	if s.ID <= 0 {
+		return errors.New("missing story id")
+	}
+	if s.Content.Component == "" {
+		return errors.New("missing content component")
+	}
+	return nil
+}
+
+// ParseStories decodes one or more stories from a single story, an array of
+// stories or a Storyblok export ({"story": ...} or {"stories": [...]}).
+func ParseStories(data []byte) ([]Story, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("no stories found")
+	}
+
+	if data[0] == '[' {
+		var ss []Story
+		err := json.Unmarshal(data, &ss)
+		return ss, err
+	}
+
+	var export struct {
+		Story   *Story  `json:"story"`
+		Stories []Story `json:"stories"`
+	}
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case export.Stories != nil:
+		return export.Stories, nil
+	case export.Story != nil:
+		return []Story{*export.Story}, nil
+	}
+
+	var s Story
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		return nil, err
+	}
+	return []Story{s}, nil
+}
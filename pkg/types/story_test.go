@@ -0,0 +1,85 @@
+package types
+
+import "testing"
+
+func TestParseStories(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []int
+	}{
+		{"single story", `{"id": 1, "content": {"component": "recipe"}}`, []int{1}},
+		{"array", `[{"id": 1}, {"id": 2}]`, []int{1, 2}},
+		{"export list", `{"stories": [{"id": 3}, {"id": 4}]}`, []int{3, 4}},
+		{"export story", `{"story": {"id": 5}}`, []int{5}},
+	}
+
+	for _, tt := range tests {
+		ss, err := ParseStories([]byte(tt.body))
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if len(ss) != len(tt.want) {
+			t.Errorf("%s: got %d stories, want %d", tt.name, len(ss), len(tt.want))
+			continue
+		}
+		for i, id := range tt.want {
+			if ss[i].ID != id {
+				t.Errorf("%s: got story ID %d, want %d", tt.name, ss[i].ID, id)
+			}
+		}
+	}
+
+	if _, err := ParseStories([]byte(`{"id": "one"}`)); err == nil {
+		t.Error("Error parsing malformed story: got no error")
+	}
+}
+
+func TestStoryValidate(t *testing.T) {
+	var s Story
+	if s.Validate() == nil {
+		t.Error("Error validating story without id: got no error")
+	}
+
+	s.ID = 1
+	s.Content.Component = "recipe"
+	if err := s.Validate(); err != nil {
+		t.Errorf("Error validating story: %s", err)
+	}
+}
+
+func TestSetTextField(t *testing.T) {
+	stories, err := ParseStories([]byte(`{
+		"id": 1,
+		"content": {
+			"title": "Titolo",
+			"image": {"alt": "Alt", "filename": "a.jpg"},
+			"steps": [{"_uid": "s1", "title": "Passo", "content": "Contenuto"}],
+			"ingredients": {"ingredients": [{"name": "Sale", "unit": "g"}]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := stories[0]
+
+	// each field listed by TextFields can be set back
+	for _, f := range s.TextFields() {
+		err := s.SetTextField(f.Path, "new "+f.Path)
+		if err != nil {
+			t.Errorf("Error setting field %s: %s", f.Path, err)
+		}
+	}
+	for _, f := range s.TextFields() {
+		if f.Value != "new "+f.Path {
+			t.Errorf("Field %s: got %q", f.Path, f.Value)
+		}
+	}
+
+	for _, path := range []string{"cost", "steps.s2.title", "ingredients.1.name", "image.filename"} {
+		if err := s.SetTextField(path, "x"); err == nil {
+			t.Errorf("Error setting field %s: got no error", path)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"strings"
+	"time"
+)
+
 type Reply struct {
 	ID      interface{} `json:"id"`
 	Stories []Story     `json:"stories"`
@@ -7,4 +12,126 @@ type Reply struct {
 
 type Request struct {
 	Message string `json:"message"`
+	Trace   Trace  `json:"trace,omitempty"`
+}
+
+// Trace carries the trace context of a jsonrpc request.
+type Trace map[string]string
+
+// SetTrace sets the trace context sent along with the request.
+func (r *Request) SetTrace(t Trace) {
+	r.Trace = t
+}
+
+// BackfillRequest asks to enqueue for translation all the stories missing a language.
+type BackfillRequest struct {
+	// ID identifies the job, submitting the same ID again resumes it.
+	ID string `json:"id"`
+	// Lang is the language code the stories must be translated into.
+	Lang string `json:"lang"`
+	// StartsWith filters the stories by full slug.
+	StartsWith string `json:"starts_with"`
+	// Query holds additional Storyblok query parameters (e.g. filter_query).
+	Query map[string]string `json:"query"`
+	// Rate is the max number of stories enqueued per second, up to 100.
+	Rate int `json:"rate"`
+}
+
+// Backfill reports the progress of a backfill job.
+type Backfill struct {
+	BackfillRequest
+	Page      int       `json:"page"`
+	Scanned   int       `json:"scanned"`
+	Enqueued  int       `json:"enqueued"`
+	Running   bool      `json:"running"`
+	Done      bool      `json:"done"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DatasourceRequest asks to translate the entries of the given datasources (all when empty).
+type DatasourceRequest struct {
+	Datasources []string `json:"datasources"`
+}
+
+// DatasourceReply lists the datasource entries sent to be translated.
+type DatasourceReply struct {
+	Entries []DatasourceEntry `json:"entries"`
+}
+
+// JobRequest asks to translate the given stories into the target languages,
+// tracking their progress.
+type JobRequest struct {
+	StoryIDs []int    `json:"story_ids"`
+	Targets  []string `json:"targets"`
+	Trace    Trace    `json:"trace,omitempty"`
 }
+
+// SetTrace sets the trace context sent along with the request.
+func (r *JobRequest) SetTrace(t Trace) {
+	r.Trace = t
+}
+
+// RetranslateRequest asks to translate the given stories into the languages
+// again, tracking their progress as a job.
+type RetranslateRequest struct {
+	StoryIDs []int    `json:"story_ids"`
+	Langs    []string `json:"langs"`
+	// Force overrides the translations saved already, otherwise the stories
+	// are saved only in the languages they miss.
+	Force bool  `json:"force"`
+	Trace Trace `json:"trace,omitempty"`
+}
+
+// SetTrace sets the trace context sent along with the request.
+func (r *RetranslateRequest) SetTrace(t Trace) {
+	r.Trace = t
+}
+
+// ForceField is the name of the stream message field listing the languages,
+// comma separated, whose translation is saved even if present on Storyblok.
+const ForceField = "force"
+
+// Forced tells whether the message values force saving the translation in the
+// given language.
+func Forced(values map[string]interface{}, code string) bool {
+	v, _ := values[ForceField].(string)
+	if v == "" {
+		return false
+	}
+	for _, lang := range strings.Split(v, ",") {
+		if lang == code {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetsField is the name of the stream message field listing the languages,
+// comma separated, the story is translated into. Messages without it are
+// translated into all the languages.
+const TargetsField = "targets"
+
+// Targeted tells whether the message values ask for the translation in any of
+// the given languages.
+func Targeted(values map[string]interface{}, codes ...string) bool {
+	v, _ := values[TargetsField].(string)
+	if v == "" {
+		return true
+	}
+	for _, lang := range strings.Split(v, ",") {
+		for _, code := range codes {
+			if lang == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DownstreamField is the name of the stream message field set on the reviewed
+// translations into the language it holds, sent to be translated into the
+// languages translated from it. They are not saved in their own language, the
+// translations made from them override the ones saved already.
+const DownstreamField = "downstream"
@@ -0,0 +1,29 @@
+package types
+
+import "testing"
+
+func TestForced(t *testing.T) {
+	values := map[string]interface{}{ForceField: "en,fr"}
+	if !Forced(values, "fr") {
+		t.Error("expected fr to be forced")
+	}
+	if Forced(values, "de") {
+		t.Error("expected de not to be forced")
+	}
+	if Forced(map[string]interface{}{}, "") {
+		t.Error("expected no language forced without the field")
+	}
+}
+
+func TestTargeted(t *testing.T) {
+	values := map[string]interface{}{TargetsField: "fr"}
+	if !Targeted(values, "en", "fr") {
+		t.Error("expected fr to be targeted")
+	}
+	if Targeted(values, "en") {
+		t.Error("expected en not to be targeted")
+	}
+	if !Targeted(map[string]interface{}{}, "de") {
+		t.Error("expected all languages targeted without the field")
+	}
+}
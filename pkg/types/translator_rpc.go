@@ -0,0 +1,15 @@
+package types
+
+// TranslateRequest asks to translate a story from the source language
+// (detected when empty) into the target language.
+type TranslateRequest struct {
+	Story  Story  `json:"story"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// TranslateReply holds the story translated into the requested language.
+type TranslateReply struct {
+	ID          interface{} `json:"id"`
+	Translation Story       `json:"translation"`
+}
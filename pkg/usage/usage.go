@@ -0,0 +1,202 @@
+// Package usage accounts the characters sent to the translation backends by
+// backend, language pair, story and day.
+//
+// The counts of each day are kept in the hash "usage:<YYYY-MM-DD>" with fields
+// "<backend>|<pair>", those of each story in "usage:<YYYY-MM-DD>:stories" with
+// fields "<backend>|<pair>|<story ID>", and the monthly totals checked against
+// the budgets in "usage:<YYYY-MM>".
+//
+// The back-translations of the quality checks are counted under their pair
+// marked as "<target>-<source>+check", against the budget of the language
+// checked.
+package usage
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	keyPrefix = "usage:"
+	dayLayout = "2006-01-02"
+	// mark of the pairs of the back-translations
+	checkSuffix = "+check"
+	// time the counts are kept for
+	retention = 400 * 24 * time.Hour
+)
+
+// Record is a text sent to a translation backend.
+type Record struct {
+	Backend string
+	Source  string
+	Target  string
+	// zero for datasource entries, not counted by story
+	StoryID    int
+	Characters int
+	// back-translation of a quality check, from the language checked
+	Check bool
+}
+
+// Count is the number of characters sent to a backend in a day.
+type Count struct {
+	Day        string `json:"day"`
+	Backend    string `json:"backend"`
+	Pair       string `json:"pair"`
+	StoryID    int    `json:"story_id,omitempty"`
+	Characters int64  `json:"characters"`
+}
+
+func dayKey(t time.Time) string {
+	return keyPrefix + t.UTC().Format(dayLayout)
+}
+
+func monthKey(t time.Time) string {
+	return keyPrefix + t.UTC().Format("2006-01")
+}
+
+// Add counts the characters of the record on the day of t.
+func Add(rdb *redis.Client, r Record, t time.Time) error {
+	pair := r.Source + "-" + r.Target
+	if r.Check {
+		pair += checkSuffix
+	}
+	field := r.Backend + "|" + pair
+	chars := int64(r.Characters)
+
+	pipe := rdb.TxPipeline()
+	pipe.HIncrBy(dayKey(t), field, chars)
+	pipe.Expire(dayKey(t), retention)
+	pipe.HIncrBy(monthKey(t), field, chars)
+	pipe.Expire(monthKey(t), retention)
+	if r.StoryID != 0 {
+		pipe.HIncrBy(dayKey(t)+":stories", field+"|"+strconv.Itoa(r.StoryID), chars)
+		pipe.Expire(dayKey(t)+":stories", retention)
+	}
+	_, err := pipe.Exec()
+	return err
+}
+
+// Used returns the characters sent to the backend on the day and in the month
+// of t, translating into the target language and checking its translations.
+func Used(rdb *redis.Client, backend, target string, t time.Time) (day, month int64, err error) {
+	pipe := rdb.Pipeline()
+	dc := pipe.HGetAll(dayKey(t))
+	mc := pipe.HGetAll(monthKey(t))
+	_, err = pipe.Exec()
+	if err != nil && err != redis.Nil {
+		return 0, 0, err
+	}
+	return sum(dc.Val(), backend, target), sum(mc.Val(), backend, target), nil
+}
+
+// sum adds up the counts of the backend counted against the budget of the
+// target language.
+func sum(counts map[string]string, backend, target string) int64 {
+	var total int64
+	for field, v := range counts {
+		c, ok := parseField(field, v)
+		if !ok || c.StoryID != 0 || c.Backend != backend || !charged(c.Pair, target) {
+			continue
+		}
+		total += c.Characters
+	}
+	return total
+}
+
+// charged tells whether the pair counts against the budget of the target
+// language: translating into it, or back-translating from it to check it.
+func charged(pair, target string) bool {
+	if strings.HasSuffix(pair, checkSuffix) {
+		return strings.HasPrefix(pair, target+"-")
+	}
+	return strings.HasSuffix(pair, "-"+target)
+}
+
+// Query selects the counts to list.
+type Query struct {
+	From time.Time
+	To   time.Time
+	// count by story, for all the stories when StoryID is zero
+	Stories bool
+	StoryID int
+}
+
+// List returns the counts of the days from the first to the last day of the
+// query, ordered by day, backend, pair and story.
+func List(rdb *redis.Client, q Query) ([]Count, error) {
+	ds := days(q.From, q.To)
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, len(ds))
+	for i, d := range ds {
+		key := dayKey(d)
+		if q.Stories || q.StoryID != 0 {
+			key += ":stories"
+		}
+		cmds[i] = pipe.HGetAll(key)
+	}
+	_, err := pipe.Exec()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	counts := []Count{}
+	for i, cmd := range cmds {
+		for field, v := range cmd.Val() {
+			c, ok := parseField(field, v)
+			if !ok || (q.StoryID != 0 && c.StoryID != q.StoryID) {
+				continue
+			}
+			c.Day = ds[i].Format(dayLayout)
+			counts = append(counts, c)
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		switch {
+		case a.Day != b.Day:
+			return a.Day < b.Day
+		case a.Backend != b.Backend:
+			return a.Backend < b.Backend
+		case a.Pair != b.Pair:
+			return a.Pair < b.Pair
+		}
+		return a.StoryID < b.StoryID
+	})
+	return counts, nil
+}
+
+// days returns the days from the day of from to the day of to, included.
+func days(from, to time.Time) []time.Time {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC()
+	var ds []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		ds = append(ds, d)
+	}
+	return ds
+}
+
+// parseField returns the count of a hash field as "<backend>|<pair>", followed
+// by "|<story ID>" for the counts by story.
+func parseField(field, value string) (Count, bool) {
+	parts := strings.Split(field, "|")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Count{}, false
+	}
+	chars, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return Count{}, false
+	}
+	c := Count{Backend: parts[0], Pair: parts[1], Characters: chars}
+	if len(parts) == 3 {
+		c.StoryID, err = strconv.Atoi(parts[2])
+		if err != nil {
+			return Count{}, false
+		}
+	}
+	return c, true
+}
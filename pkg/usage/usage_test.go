@@ -0,0 +1,69 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDays(t *testing.T) {
+	from := time.Date(2020, 2, 27, 18, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	ds := days(from, to)
+	expected := []string{"2020-02-27", "2020-02-28", "2020-02-29", "2020-03-01"}
+	if len(ds) != len(expected) {
+		t.Fatalf("expected %d days, got %v", len(expected), ds)
+	}
+	for i, d := range expected {
+		if ds[i].Format(dayLayout) != d {
+			t.Errorf("expected day %s, got %s", d, ds[i].Format(dayLayout))
+		}
+	}
+
+	if ds := days(to, from); len(ds) != 0 {
+		t.Errorf("expected no days, got %v", ds)
+	}
+}
+
+func TestSum(t *testing.T) {
+	counts := map[string]string{
+		"google|it-en":    "1200",
+		"google|fr-en":    "300",
+		"google|en-fr":    "500",
+		"gtx|it-en":       "4000",
+		"google|it-en|42": "1200",
+		"google|it-de":    "oops",
+		// back-translations checking english and french
+		"google|en-it+check": "1100",
+		"google|fr-en+check": "400",
+	}
+
+	if total := sum(counts, "google", "en"); total != 2600 {
+		t.Errorf("expected 2600 characters into english, got %d", total)
+	}
+	if total := sum(counts, "google", "fr"); total != 900 {
+		t.Errorf("expected 900 characters into french, got %d", total)
+	}
+	if total := sum(counts, "google", "it"); total != 0 {
+		t.Errorf("expected no characters into italian, got %d", total)
+	}
+	if total := sum(counts, "gtx", "fr"); total != 0 {
+		t.Errorf("expected no characters, got %d", total)
+	}
+}
+
+func TestParseField(t *testing.T) {
+	c, ok := parseField("google|it-en|42", "120")
+	if !ok {
+		t.Fatal("expected valid field")
+	}
+	if c != (Count{Backend: "google", Pair: "it-en", StoryID: 42, Characters: 120}) {
+		t.Errorf("unexpected count %+v", c)
+	}
+
+	for _, field := range []string{"google", "google|it-en|story", "a|b|c|d"} {
+		if _, ok := parseField(field, "1"); ok {
+			t.Errorf("expected field %q to be invalid", field)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/kind84/polygo/pkg/config"
+	"github.com/kind84/polygo/polygoctl/polygoctl"
+)
+
+const usage = `polygoctl inspects and repairs the polygo streams and consumer groups.
+
+Usage:
+
+	polygoctl streams
+	polygoctl pending [-consumer name] [-idle duration] [-count n] <stream> <group>
+	polygoctl inspect <stream> <id>
+	polygoctl ack <stream> <group> <id>...
+	polygoctl del [-group name] <stream> <id>...
+	polygoctl reset <stream> <group> <id|0|$>
+	polygoctl claim [-idle duration] <stream> <group> <consumer> [id]...
+	polygoctl requeue <dead-letter stream> <stream> [id]...
+
+Redis is reached at redis.host, read from config.yaml in the working
+directory or from POLYGO_REDIS_HOST.
+`
+
+type command struct {
+	// number of positional arguments, at least min and at most max (unlimited when -1)
+	min, max int
+	// setup defines the flags of the command and returns the function running it
+	setup func(fs *flag.FlagSet) func(a *polygoctl.Admin, args []string) error
+}
+
+var commands = map[string]command{
+	"streams": {0, 0, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		return streams
+	}},
+	"pending": {2, 2, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		consumer := fs.String("consumer", "", "only the messages of the consumer")
+		idle := fs.Duration("idle", 0, "only the messages idle for longer")
+		count := fs.Int64("count", 100, "max messages listed")
+		return func(a *polygoctl.Admin, args []string) error {
+			return pending(a, polygoctl.PendingArgs{
+				Stream:   args[0],
+				Group:    args[1],
+				Consumer: *consumer,
+				MinIdle:  *idle,
+				Count:    *count,
+			})
+		}
+	}},
+	"inspect": {2, 2, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		return inspect
+	}},
+	"ack": {3, -1, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		return ack
+	}},
+	"del": {2, -1, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		group := fs.String("group", "", "acknowledge the messages for the group before deleting them")
+		return func(a *polygoctl.Admin, args []string) error {
+			n, err := a.Delete(args[0], *group, args[1:]...)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%d deleted\n", n)
+			return nil
+		}
+	}},
+	"reset": {3, 3, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		return reset
+	}},
+	"claim": {3, -1, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		idle := fs.Duration("idle", 0, "only the messages idle for longer")
+		return func(a *polygoctl.Admin, args []string) error {
+			return claim(a, polygoctl.ClaimArgs{
+				Stream:   args[0],
+				Group:    args[1],
+				Consumer: args[2],
+				MinIdle:  *idle,
+				IDs:      args[3:],
+			})
+		}
+	}},
+	"requeue": {2, -1, func(fs *flag.FlagSet) func(*polygoctl.Admin, []string) error {
+		return requeue
+	}},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	run := cmd.setup(fs)
+	fs.Parse(os.Args[2:])
+	args := fs.Args()
+	if len(args) < cmd.min || (cmd.max >= 0 && len(args) > cmd.max) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	err := config.Init()
+	if err != nil {
+		fail(err)
+	}
+	var conf config.Ctl
+	err = config.Load(&conf)
+	if err != nil {
+		fail(err)
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: conf.Redis.Host})
+	defer rdb.Close()
+
+	err = run(polygoctl.New(rdb), args)
+	if err != nil {
+		rdb.Close()
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "polygoctl: %s\n", err)
+	os.Exit(1)
+}
+
+func streams(a *polygoctl.Admin, _ []string) error {
+	ss, err := a.Streams()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STREAM\tLENGTH\tGROUP\tCONSUMERS\tPENDING\tLAG\tLAST DELIVERED")
+	for _, s := range ss {
+		if len(s.Groups) == 0 {
+			fmt.Fprintf(w, "%s\t%d\t-\t\t\t\t\n", s.Name, s.Length)
+		}
+		for _, g := range s.Groups {
+			lag := "-"
+			if g.Lag >= 0 {
+				lag = fmt.Sprint(g.Lag)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\t%s\t%s\n",
+				s.Name, s.Length, g.Name, g.Consumers, g.Pending, lag, g.LastDeliveredID)
+		}
+	}
+	return w.Flush()
+}
+
+func pending(a *polygoctl.Admin, args polygoctl.PendingArgs) error {
+	p, err := a.Pending(args)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CONSUMER\tPENDING")
+	consumers := make([]string, 0, len(p.Consumers))
+	for consumer := range p.Consumers {
+		consumers = append(consumers, consumer)
+	}
+	sort.Strings(consumers)
+	for _, consumer := range consumers {
+		fmt.Fprintf(w, "%s\t%d\n", consumer, p.Consumers[consumer])
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ID\tCONSUMER\tIDLE\tDELIVERIES")
+	for _, msg := range p.Messages {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", msg.Id, msg.Consumer, msg.Idle.Truncate(time.Second), msg.RetryCount)
+	}
+	return w.Flush()
+}
+
+func inspect(a *polygoctl.Admin, args []string) error {
+	msg, err := a.Message(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Print(polygoctl.FormatMessage(msg))
+	return nil
+}
+
+func ack(a *polygoctl.Admin, args []string) error {
+	n, err := a.Ack(args[0], args[1], args[2:]...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d acknowledged\n", n)
+	return nil
+}
+
+func reset(a *polygoctl.Admin, args []string) error {
+	err := a.Reset(args[0], args[1], args[2])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("group %s of %s set to %s\n", args[1], args[0], args[2])
+	return nil
+}
+
+func claim(a *polygoctl.Admin, args polygoctl.ClaimArgs) error {
+	ids, err := a.Claim(args)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d claimed by %s\n", len(ids), args.Consumer)
+	if len(ids) > 0 {
+		fmt.Println(strings.Join(ids, "\n"))
+	}
+	return nil
+}
+
+func requeue(a *polygoctl.Admin, args []string) error {
+	ids, err := a.Requeue(args[0], args[1], args[2:]...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d requeued on %s\n", len(ids), args[1])
+	if len(ids) > 0 {
+		fmt.Println(strings.Join(ids, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+// Package polygoctl inspects and repairs the redis streams and consumer groups
+// the services communicate through.
+package polygoctl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// ErrNotFound is returned when the message is not on the stream.
+var ErrNotFound = errors.New("message not found")
+
+// Admin runs the admin commands against redis.
+type Admin struct {
+	rdb *redis.Client
+}
+
+// New returns an admin of the streams on the given redis.
+func New(rdb *redis.Client) *Admin {
+	return &Admin{rdb: rdb}
+}
+
+// Stream describes a stream and its consumer groups.
+type Stream struct {
+	Name   string
+	Length int64
+	Groups []Group
+}
+
+// Group describes a consumer group of a stream.
+type Group struct {
+	Name      string
+	Consumers int64
+	// messages delivered to the group and not acknowledged yet
+	Pending int64
+	// messages not delivered to the group yet, -1 when redis does not report it
+	// (before redis 7)
+	Lag             int64
+	LastDeliveredID string
+}
+
+// Streams returns the streams on redis with their consumer groups, sorted by name.
+func (a *Admin) Streams() ([]Stream, error) {
+	keys, err := a.streamKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make([]Stream, 0, len(keys))
+	for _, key := range keys {
+		n, err := a.rdb.XLen(key).Result()
+		if err != nil {
+			return nil, err
+		}
+		res, err := a.rdb.Do("XINFO", "GROUPS", key).Result()
+		if err != nil {
+			return nil, err
+		}
+		groups, err := parseGroups(res)
+		if err != nil {
+			return nil, fmt.Errorf("stream %s: %s", key, err)
+		}
+		streams = append(streams, Stream{Name: key, Length: n, Groups: groups})
+	}
+	return streams, nil
+}
+
+// streamKeys returns the sorted keys holding a stream.
+func (a *Admin) streamKeys() ([]string, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+	for {
+		batch, next, err := a.rdb.Scan(cursor, "*", 100).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		types := make([]*redis.StatusCmd, len(batch))
+		_, err = a.rdb.Pipelined(func(pipe redis.Pipeliner) error {
+			for i, key := range batch {
+				types[i] = pipe.Type(key)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range batch {
+			if types[i].Val() == "stream" {
+				keys = append(keys, key)
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// parseGroups reads the reply of XINFO GROUPS.
+func parseGroups(res interface{}) ([]Group, error) {
+	replies, ok := res.([]interface{})
+	if !ok {
+		return nil, errors.New("unexpected XINFO reply")
+	}
+
+	groups := make([]Group, 0, len(replies))
+	for _, reply := range replies {
+		fields, _ := reply.([]interface{})
+		g := Group{Lag: -1}
+		for i := 0; i+1 < len(fields); i += 2 {
+			k, _ := fields[i].(string)
+			switch k {
+			case "name":
+				g.Name, _ = fields[i+1].(string)
+			case "consumers":
+				g.Consumers, _ = integer(fields[i+1])
+			case "pending":
+				g.Pending, _ = integer(fields[i+1])
+			case "lag":
+				if n, ok := integer(fields[i+1]); ok {
+					g.Lag = n
+				}
+			case "last-delivered-id":
+				g.LastDeliveredID, _ = fields[i+1].(string)
+			}
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+func integer(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// Pending is the pending list of a consumer group.
+type Pending struct {
+	// pending messages by consumer
+	Consumers map[string]int64
+	// pending messages, oldest first
+	Messages []redis.XPendingExt
+}
+
+// PendingArgs select the pending messages to list.
+type PendingArgs struct {
+	Stream   string
+	Group    string
+	Consumer string
+	// only messages idle for longer
+	MinIdle time.Duration
+	Count   int64
+}
+
+// Pending returns the messages delivered to the consumers of a group and not
+// acknowledged yet.
+func (a *Admin) Pending(args PendingArgs) (*Pending, error) {
+	summary, err := a.rdb.XPending(args.Stream, args.Group).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	count := args.Count
+	if count <= 0 {
+		count = 100
+	}
+	msgs, err := a.pendingIdle(args.Stream, args.Group, args.Consumer, args.MinIdle, count)
+	if err != nil {
+		return nil, err
+	}
+	return &Pending{Consumers: summary.Consumers, Messages: msgs}, nil
+}
+
+// pendingIdle pages through the pending list until count messages idle for
+// at least minIdle are found, all of them when count is not positive.
+func (a *Admin) pendingIdle(stream, group, consumer string, minIdle time.Duration, count int64) ([]redis.XPendingExt, error) {
+	const pageSize = 100
+
+	var msgs []redis.XPendingExt
+	start := "-"
+	for {
+		page, err := a.rdb.XPendingExt(&redis.XPendingExtArgs{
+			Stream:   stream,
+			Group:    group,
+			Start:    start,
+			End:      "+",
+			Count:    pageSize,
+			Consumer: consumer,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, msg := range page {
+			if msg.Idle < minIdle {
+				continue
+			}
+			msgs = append(msgs, msg)
+			if count > 0 && int64(len(msgs)) == count {
+				return msgs, nil
+			}
+		}
+		if len(page) < pageSize {
+			return msgs, nil
+		}
+		start = nextID(page[len(page)-1].Id)
+	}
+}
+
+// nextID returns the stream ID following the given one.
+func nextID(id string) string {
+	sep := strings.Index(id, "-")
+	if sep < 0 {
+		return id
+	}
+	ms, err := strconv.ParseUint(id[:sep], 10, 64)
+	if err != nil {
+		return id
+	}
+	seq, err := strconv.ParseUint(id[sep+1:], 10, 64)
+	if err != nil {
+		return id
+	}
+	if seq == math.MaxUint64 {
+		return strconv.FormatUint(ms+1, 10) + "-0"
+	}
+	return strconv.FormatUint(ms, 10) + "-" + strconv.FormatUint(seq+1, 10)
+}
+
+// ClaimArgs select the pending messages to move to another consumer.
+type ClaimArgs struct {
+	Stream   string
+	Group    string
+	Consumer string
+	// only messages idle for longer
+	MinIdle time.Duration
+	// messages claimed, all the pending messages idle for longer when empty
+	IDs []string
+}
+
+// Claim moves pending messages to the consumer, typically from a consumer
+// renamed or gone, so that it reads and retries them. It returns the IDs of
+// the messages claimed.
+func (a *Admin) Claim(args ClaimArgs) ([]string, error) {
+	ids := args.IDs
+	if len(ids) == 0 {
+		msgs, err := a.pendingIdle(args.Stream, args.Group, "", args.MinIdle, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			if msg.Consumer != args.Consumer {
+				ids = append(ids, msg.Id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return a.rdb.XClaimJustID(&redis.XClaimArgs{
+		Stream:   args.Stream,
+		Group:    args.Group,
+		Consumer: args.Consumer,
+		MinIdle:  args.MinIdle,
+		Messages: ids,
+	}).Result()
+}
+
+// Message returns the message with the given ID.
+func (a *Admin) Message(stream, id string) (redis.XMessage, error) {
+	msgs, err := a.rdb.XRangeN(stream, id, id, 1).Result()
+	if err != nil {
+		return redis.XMessage{}, err
+	}
+	if len(msgs) == 0 {
+		return redis.XMessage{}, ErrNotFound
+	}
+	return msgs[0], nil
+}
+
+// Ack acknowledges the messages for the group, returning the number of
+// messages removed from its pending list.
+func (a *Admin) Ack(stream, group string, ids ...string) (int64, error) {
+	return a.rdb.XAck(stream, group, ids...).Result()
+}
+
+// Delete removes the messages from the stream, returning the number of
+// messages deleted. When group is set the messages are acknowledged for the
+// group first, since deleted messages stay on the pending lists otherwise.
+func (a *Admin) Delete(stream, group string, ids ...string) (int64, error) {
+	var del *redis.IntCmd
+	_, err := a.rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		if group != "" {
+			pipe.XAck(stream, group, ids...)
+		}
+		del = pipe.XDel(stream, ids...)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return del.Val(), nil
+}
+
+// Reset moves the last delivered ID of the group to id: "0" redelivers the
+// whole stream, "$" skips the messages on the stream.
+func (a *Admin) Reset(stream, group, id string) error {
+	return a.rdb.XGroupSetID(stream, group, id).Err()
+}
+
+// Requeue moves the messages with the given IDs, or all of them when none is
+// given, from a dead-letter stream back to the stream they are consumed from.
+// The services do not write dead-letter streams: Claim hands their failed
+// messages, left pending, to another consumer.
+// It returns the IDs of the messages added to the stream.
+func (a *Admin) Requeue(from, to string, ids ...string) ([]string, error) {
+	var msgs []redis.XMessage
+	if len(ids) == 0 {
+		var err error
+		msgs, err = a.rdb.XRange(from, "-", "+").Result()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range ids {
+		msg, err := a.Message(from, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", id, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+
+	xadds := make([]*redis.StringCmd, len(msgs))
+	_, err := a.rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		for i, msg := range msgs {
+			xadds[i] = pipe.XAdd(&redis.XAddArgs{
+				Stream: to,
+				Values: msg.Values,
+			})
+			pipe.XDel(from, msg.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	added := make([]string, len(xadds))
+	for i, xadd := range xadds {
+		added[i] = xadd.Val()
+	}
+	return added, nil
+}
@@ -0,0 +1,84 @@
+package polygoctl
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis"
+)
+
+func TestParseGroups(t *testing.T) {
+	res := []interface{}{
+		[]interface{}{
+			"name", "translate_it-en",
+			"consumers", int64(2),
+			"pending", int64(3),
+			"last-delivered-id", "1-0",
+			"entries-read", int64(4),
+			"lag", int64(5),
+		},
+		// redis 6 reports no lag
+		[]interface{}{
+			"name", "storybloks_en",
+			"consumers", int64(1),
+			"pending", int64(0),
+			"last-delivered-id", "0-0",
+		},
+	}
+
+	groups, err := parseGroups(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Group{
+		{Name: "storybloks_en", Consumers: 1, Pending: 0, Lag: -1, LastDeliveredID: "0-0"},
+		{Name: "translate_it-en", Consumers: 2, Pending: 3, Lag: 5, LastDeliveredID: "1-0"},
+	}
+	if len(groups) != len(expected) {
+		t.Fatalf("expected %d groups, got %v", len(expected), groups)
+	}
+	for i, g := range expected {
+		if groups[i] != g {
+			t.Errorf("expected group %+v, got %+v", g, groups[i])
+		}
+	}
+
+	_, err = parseGroups("OK")
+	if err == nil {
+		t.Error("expected an error for an unexpected reply")
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	msg := redis.XMessage{
+		ID: "1-0",
+		Values: map[string]interface{}{
+			"story":       `{"id":42,"name":"Home"}`,
+			"traceparent": "00-abc-def-01",
+		},
+	}
+
+	expected := "id: 1-0\n" +
+		"story:\n" +
+		"  {\n" +
+		"    \"id\": 42,\n" +
+		"    \"name\": \"Home\"\n" +
+		"  }\n" +
+		"traceparent: 00-abc-def-01\n"
+	if got := FormatMessage(msg); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestNextID(t *testing.T) {
+	tests := map[string]string{
+		"1526919030474-0":                    "1526919030474-1",
+		"1526919030474-41":                   "1526919030474-42",
+		"1526919030474-18446744073709551615": "1526919030475-0",
+		"oops":                               "oops",
+	}
+	for id, want := range tests {
+		if next := nextID(id); next != want {
+			t.Errorf("nextID(%q): got %q, want %q", id, next, want)
+		}
+	}
+}
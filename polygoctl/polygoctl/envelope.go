@@ -0,0 +1,38 @@
+package polygoctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-redis/redis"
+)
+
+// FormatMessage writes the envelope of a stream message, one field per line
+// sorted by name, with the JSON payloads indented.
+func FormatMessage(msg redis.XMessage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: %s\n", msg.ID)
+
+	keys := make([]string, 0, len(msg.Values))
+	for k := range msg.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := fmt.Sprint(msg.Values[k])
+
+		var indented bytes.Buffer
+		trimmed := strings.TrimSpace(v)
+		if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) &&
+			json.Indent(&indented, []byte(trimmed), "  ", "  ") == nil {
+			fmt.Fprintf(&b, "%s:\n  %s\n", k, indented.String())
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", k, v)
+	}
+	return b.String()
+}
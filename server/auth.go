@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/config"
+)
+
+// scope of the operations allowed to an API key.
+type scope string
+
+const (
+	scopeRead    scope = "read"
+	scopeEnqueue scope = "enqueue"
+	// admin keys are allowed every operation
+	scopeAdmin scope = "admin"
+)
+
+// max size of the webhook payloads
+const maxWebhookBody = 1 << 20
+
+var (
+	errUnauthorized = errors.New("missing or invalid API key")
+	errForbidden    = errors.New("API key not allowed to perform this operation")
+	errSignature    = errors.New("missing or invalid webhook signature")
+)
+
+// authenticator checks the API keys and the webhook signatures of the requests.
+type authenticator struct {
+	// scopes granted to each key, by sha256 of the key
+	keys          map[[sha256.Size]byte]map[scope]bool
+	webhookSecret []byte
+}
+
+// newAuthenticator returns an authenticator granting to each API key its list
+// of scopes and verifying webhooks signed with the given secret.
+func newAuthenticator(keys []config.APIKey, webhookSecret string) *authenticator {
+	a := &authenticator{
+		keys:          make(map[[sha256.Size]byte]map[scope]bool, len(keys)),
+		webhookSecret: []byte(webhookSecret),
+	}
+	for _, k := range keys {
+		granted := make(map[scope]bool, len(k.Scopes))
+		for _, s := range k.Scopes {
+			granted[scope(strings.TrimSpace(s))] = true
+		}
+		a.keys[sha256.Sum256([]byte(k.Key))] = granted
+	}
+	return a
+}
+
+// require wraps the handler allowing only requests with an API key granted the scope.
+// The key is sent either as bearer token or in the X-API-Key header.
+func (a *authenticator) require(s scope, h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		key := req.Header.Get("X-API-Key")
+		if auth := req.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		// keys are looked up by hash to avoid leaking them through timing
+		granted, ok := a.keys[sha256.Sum256([]byte(key))]
+		if key == "" || !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="polygo"`)
+			writeError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		if !granted[s] && !granted[scopeAdmin] {
+			writeError(w, http.StatusForbidden, errForbidden)
+			return
+		}
+
+		h(w, req, ps)
+	}
+}
+
+// signed wraps the webhook handler allowing only requests whose body is signed
+// with the webhook secret (hex encoded HMAC-SHA1 in the webhook-signature header,
+// as sent by Storyblok).
+func (a *authenticator) signed(h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxWebhookBody))
+		if err != nil {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		sig, err := hex.DecodeString(req.Header.Get("webhook-signature"))
+		if err != nil || len(a.webhookSecret) == 0 {
+			writeError(w, http.StatusUnauthorized, errSignature)
+			return
+		}
+
+		mac := hmac.New(sha1.New, a.webhookSecret)
+		mac.Write(body)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			writeError(w, http.StatusUnauthorized, errSignature)
+			return
+		}
+
+		// let the handler read the body again
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		h(w, req, ps)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/config"
+)
+
+func ok(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestAuthRequire(t *testing.T) {
+	auth := newAuthenticator([]config.APIKey{
+		{Key: "reader", Scopes: []string{"read"}},
+		{Key: "root", Scopes: []string{"admin"}},
+		{Key: "MixedCase", Scopes: []string{"read"}},
+	}, "")
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		scope  scope
+		want   int
+	}{
+		{"no key", "", "", scopeRead, http.StatusUnauthorized},
+		{"unknown key", "X-API-Key", "nope", scopeRead, http.StatusUnauthorized},
+		{"granted scope", "X-API-Key", "reader", scopeRead, http.StatusOK},
+		{"bearer token", "Authorization", "Bearer reader", scopeRead, http.StatusOK},
+		{"missing scope", "X-API-Key", "reader", scopeEnqueue, http.StatusForbidden},
+		{"admin", "Authorization", "Bearer root", scopeEnqueue, http.StatusOK},
+		{"mixed case key", "X-API-Key", "MixedCase", scopeRead, http.StatusOK},
+		{"key case differs", "X-API-Key", "mixedcase", scopeRead, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		w := httptest.NewRecorder()
+
+		auth.require(tt.scope, ok)(w, req, nil)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestAuthSigned(t *testing.T) {
+	auth := newAuthenticator(nil, "secret")
+	body := `{"task":{"id":1,"name":"translate"},"space_id":2}`
+
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(body))
+	valid := hex.EncodeToString(mac.Sum(nil))
+
+	for sig, want := range map[string]int{
+		valid:    http.StatusOK,
+		"":       http.StatusUnauthorized,
+		"abcdef": http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("webhook-signature", sig)
+		w := httptest.NewRecorder()
+
+		auth.signed(ok)(w, req, nil)
+		if w.Code != want {
+			t.Errorf("signature %q: got status %d, want %d", sig, w.Code, want)
+		}
+	}
+}
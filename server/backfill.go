@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// startBackfill starts or resumes a backfill job enqueueing the stories missing a language.
+func startBackfill(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var bfReq types.BackfillRequest
+	err := json.NewDecoder(req.Body).Decode(&bfReq)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var bf types.Backfill
+	err = callStoryBlok(req.Context(), "StoryBlok.Backfill", &bfReq, &bf)
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, bf)
+}
+
+// backfillProgress reports the progress of a backfill job.
+func backfillProgress(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// catch-all parameter, job IDs may contain slashes
+	id := strings.TrimPrefix(ps.ByName("id"), "/")
+
+	var bf types.Backfill
+	err := callStoryBlok(req.Context(), "StoryBlok.BackfillProgress", &id, &bf)
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bf)
+}
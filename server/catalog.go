@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/text/language"
+
+	"github.com/kind84/polygo/pkg/catalog"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/tracing"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// language of the stories on Storyblok, the source of the documents
+const defaultSource = "it"
+
+// max size of the documents imported
+const maxCatalogBody = 10 << 20
+
+// catalogFormat is a format of the documents exchanged with the translators.
+type catalogFormat struct {
+	name        string
+	contentType string
+	extension   string
+	export      func(d *catalog.Document, q url.Values) ([]byte, error)
+	parse       func(data []byte) (*catalog.Document, error)
+}
+
+var (
+	xliffFormat = catalogFormat{
+		name:        "xliff",
+		contentType: "application/xliff+xml",
+		extension:   "xlf",
+		export: func(d *catalog.Document, q url.Values) ([]byte, error) {
+			version := q.Get("version")
+			if version == "" {
+				version = catalog.XLIFF12
+			}
+			return catalog.ExportXLIFF(d, version)
+		},
+		parse: catalog.ParseXLIFF,
+	}
+	poFormat = catalogFormat{
+		name:        "po",
+		contentType: "text/x-gettext-translation",
+		extension:   "po",
+		export: func(d *catalog.Document, _ url.Values) ([]byte, error) {
+			return catalog.ExportPO(d)
+		},
+		parse: catalog.ParsePO,
+	}
+	csvFormat = catalogFormat{
+		name:        "csv",
+		contentType: "text/csv; charset=utf-8",
+		extension:   "csv",
+		export: func(d *catalog.Document, _ url.Values) ([]byte, error) {
+			return catalog.ExportCSV(d)
+		},
+		parse: catalog.ParseCSV,
+	}
+)
+
+// exportCatalog sends the translatable fields of the stories in the story_ids
+// query parameter, comma separated, as a document to be translated into lang.
+func exportCatalog(f catalogFormat) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		q := req.URL.Query()
+		target := q.Get("lang")
+		if _, err := language.Parse(target); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid language %q", target))
+			return
+		}
+
+		var ids []int
+		for _, v := range strings.Split(q.Get("story_ids"), ",") {
+			if v == "" {
+				continue
+			}
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid story ID %q", v))
+				return
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) == 0 {
+			writeError(w, http.StatusBadRequest, errors.New("at least one story ID is required"))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(conf.Server.JobTimeout, jobTimeout))
+		defer cancel()
+
+		d := &catalog.Document{SourceLang: defaultSource, TargetLang: target}
+		for _, id := range ids {
+			story, err := getStory(ctx, id)
+			if err != nil {
+				writeRPCError(w, err)
+				return
+			}
+			d.Units = append(d.Units, catalog.Units(story)...)
+		}
+
+		doc, err := f.export(d, q)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", f.contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="polygo-%s.%s"`, target, f.extension))
+		w.Write(doc)
+	}
+}
+
+// importCatalog applies the translations of the document in the request body
+// to the current stories and puts them on the stream to be saved on
+// Storyblok, overriding the translations saved already. Documents with units
+// missing or not translated, or translated from a source text changed since,
+// are rejected.
+func importCatalog(f catalogFormat) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxCatalogBody))
+		if err != nil {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		d, err := f.parse(body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if _, err := language.Parse(d.TargetLang); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid target language %q", d.TargetLang))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(conf.Server.JobTimeout, jobTimeout))
+		defer cancel()
+
+		// apply the translations to the current stories before enqueueing any of them
+		stories := make([]types.Story, 0, len(d.StoryIDs()))
+		for _, id := range d.StoryIDs() {
+			story, err := getStory(ctx, id)
+			if err != nil {
+				writeRPCError(w, err)
+				return
+			}
+			err = catalog.Apply(&story, d.Units)
+			if err != nil {
+				writeError(w, http.StatusUnprocessableEntity, err)
+				return
+			}
+			stories = append(stories, story)
+		}
+
+		xadds := make([]*redis.StringCmd, 0, len(stories))
+		_, err = rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+			for _, story := range stories {
+				js, err := json.Marshal(story)
+				if err != nil {
+					return err
+				}
+
+				values := map[string]interface{}{
+					"story":          js,
+					"lang":           d.TargetLang,
+					types.ForceField: d.TargetLang,
+				}
+				tracing.Inject(req.Context(), values)
+
+				xadds = append(xadds, pipe.XAdd(&redis.XAddArgs{
+					Stream: catalog.ImportStream,
+					Values: values,
+				}))
+			}
+			return nil
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		metrics.StoriesEnqueued.WithLabelValues(f.name).Add(float64(len(xadds)))
+
+		resp := struct {
+			MessageIDs []string `json:"message_ids"`
+		}{MessageIDs: make([]string, 0, len(xadds))}
+		for i, xadd := range xadds {
+			logging.With(logging.Fields{
+				"stream":     catalog.ImportStream,
+				"message_id": xadd.Val(),
+				"story_id":   stories[i].ID,
+				"lang":       d.TargetLang,
+			}).Infof("Sending imported translation of story ID %d", stories[i].ID)
+			resp.MessageIDs = append(resp.MessageIDs, xadd.Val())
+		}
+
+		writeJSON(w, http.StatusAccepted, resp)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// rpcDatasources sends the datasource entries missing translations to be translated.
+func rpcDatasources(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var dsReq types.DatasourceRequest
+	err := json.NewDecoder(req.Body).Decode(&dsReq)
+	if err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var reply types.DatasourceReply
+	err = callStoryBlok(req.Context(), "StoryBlok.NewDatasourceEntries", &dsReq, &reply)
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, reply)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/dryrun"
+)
+
+// listDiffs lists the diffs recorded by the dry runs, newest first, filtered
+// by the story_id and lang given in the query.
+func listDiffs(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var f dryrun.Filter
+	var err error
+	q := req.URL.Query()
+	if v := q.Get("story_id"); v != "" {
+		f.StoryID, err = strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if v := q.Get("count"); v != "" {
+		f.Count, err = strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	f.Lang = q.Get("lang")
+
+	diffs, err := dryrun.List(rdb, f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := struct {
+		Diffs []dryrun.Diff `json:"diffs"`
+	}{Diffs: diffs}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func getDiff(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	d, err := dryrun.Get(rdb, ps.ByName("id"))
+	if err == dryrun.ErrNotFound {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, d)
+}
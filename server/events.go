@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+const (
+	// time waited for new events by each read, bounding the time taken to
+	// notice a client gone
+	eventsBlock = time.Second
+	// time without events before sending a keepalive
+	eventsKeepalive = 15 * time.Second
+)
+
+// jobEvents streams the events of a job as Server-Sent Events. Clients reconnecting
+// with the Last-Event-ID header receive the events following the last one seen.
+func jobEvents(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	id := ps.ByName("id")
+	j, err := job.Get(rdb, id)
+	if err == job.ErrNotFound {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// replay all the events of the job unless resuming, skipping the events
+	// of the jobs created before
+	lastID := req.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = j.EventsAfter()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", 3000)
+	flusher.Flush()
+
+	ctx := req.Context()
+	lastWrite := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		streams, err := rdb.XRead(&redis.XReadArgs{
+			Streams: []string{job.EventsStream, lastID},
+			Count:   100,
+			Block:   eventsBlock,
+		}).Result()
+		if err == redis.Nil {
+			if time.Since(lastWrite) >= eventsKeepalive {
+				// comment line to keep the connection open
+				fmt.Fprint(w, ": keepalive\n\n")
+				flusher.Flush()
+				lastWrite = time.Now()
+			}
+			continue
+		}
+		if err != nil {
+			logging.With(logging.Fields{"job": id}).Errorf("Error reading job events: %s", err)
+			return
+		}
+
+		for _, msg := range streams[0].Messages {
+			lastID = msg.ID
+
+			ev := job.EventFromMessage(msg)
+			if ev.Job != id {
+				continue
+			}
+
+			js, err := json.Marshal(ev)
+			if err != nil {
+				logging.With(logging.Fields{"job": id}).Errorf("Error encoding job event: %s", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.State, js)
+			lastWrite = time.Now()
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kind84/polygo/pkg/health"
+	"github.com/kind84/polygo/pkg/pb"
+	"github.com/kind84/polygo/pkg/tracing"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// grpc clients shared by the handlers
+var (
+	storyblokClient  pb.StoryBlokClient
+	translatorClient pb.TranslatorClient
+
+	storyblokHealth  healthpb.HealthClient
+	translatorHealth healthpb.HealthClient
+)
+
+// dialServices connects to the grpc servers of storyblok and translator. The
+// connections are established in background and retried when they drop.
+func dialServices(storyblokHost, translatorHost string) (func(), error) {
+	opts := append([]grpc.DialOption{grpc.WithInsecure()}, tracing.DialOptions()...)
+
+	sc, err := grpc.Dial(storyblokHost, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tc, err := grpc.Dial(translatorHost, opts...)
+	if err != nil {
+		sc.Close()
+		return nil, err
+	}
+
+	storyblokClient = pb.NewStoryBlokClient(sc)
+	translatorClient = pb.NewTranslatorClient(tc)
+	storyblokHealth = healthpb.NewHealthClient(sc)
+	translatorHealth = healthpb.NewHealthClient(tc)
+
+	return func() {
+		sc.Close()
+		tc.Close()
+	}, nil
+}
+
+// serving checks that the grpc service reports itself as serving.
+func serving(client healthpb.HealthClient, service string) health.Check {
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("%s is %s", service, res.GetStatus())
+		}
+		return nil
+	}
+}
+
+// newStories enqueues the stories published on Storyblok since the last run.
+func newStories(ctx context.Context) ([]types.Story, error) {
+	reply, err := storyblokClient.NewStories(ctx, &pb.NewStoriesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	ss := make([]types.Story, 0, len(reply.GetStories()))
+	for _, msg := range reply.GetStories() {
+		story, err := msg.Decode()
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, story)
+	}
+	return ss, nil
+}
+
+// getStory fetches the story with the given ID from Storyblok.
+func getStory(ctx context.Context, id int) (types.Story, error) {
+	msg, err := storyblokClient.GetStory(ctx, &pb.GetStoryRequest{Id: int64(id)})
+	if err != nil {
+		return types.Story{}, err
+	}
+	return msg.Decode()
+}
+
+// translateStory translates the story into each target language over a single
+// stream, returning the translations by language.
+func translateStory(ctx context.Context, story types.Story, source string, targets []string) (map[string]types.Story, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	msg, err := pb.NewStory(story)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := translatorClient.TranslateStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		err = stream.Send(&pb.TranslateRequest{
+			Story:  msg,
+			Source: source,
+			Target: target,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = stream.CloseSend()
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make(map[string]types.Story, len(targets))
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if reply.GetError() != "" {
+			// the error carries the status code of the failed translation
+			return nil, statusError(reply.GetError())
+		}
+
+		translation, err := reply.GetTranslation().Decode()
+		if err != nil {
+			return nil, err
+		}
+		translations[reply.GetTarget()] = translation
+	}
+
+	for _, target := range targets {
+		if _, ok := translations[target]; !ok {
+			return nil, fmt.Errorf("translation into %s missing from the translator reply", target)
+		}
+	}
+	return translations, nil
+}
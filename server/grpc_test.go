@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/kind84/polygo/pkg/pb"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// fakeTranslator replies with the story name suffixed by the target language.
+type fakeTranslator struct{}
+
+func (fakeTranslator) Translate(ctx context.Context, req *pb.TranslateRequest) (*pb.TranslateReply, error) {
+	return nil, nil
+}
+
+func (fakeTranslator) TranslateStream(stream pb.Translator_TranslateStreamServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		reply := &pb.TranslateReply{Target: req.GetTarget()}
+		story, _ := req.GetStory().Decode()
+		switch req.GetTarget() {
+		case "xx":
+			reply.Error = status.Error(codes.InvalidArgument, "unsupported language").Error()
+		case "zz":
+			reply.Error = status.Error(codes.ResourceExhausted, "budget used up").Error()
+		default:
+			story.Name += " " + req.GetTarget()
+			reply.Translation, _ = pb.NewStory(story)
+		}
+		err = stream.Send(reply)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestTranslateStory(t *testing.T) {
+	l := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	pb.RegisterTranslatorServer(gs, fakeTranslator{})
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithDialer(
+		func(string, time.Duration) (net.Conn, error) { return l.Dial() },
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	translatorClient = pb.NewTranslatorClient(conn)
+
+	story := types.Story{ID: 1, Name: "ricetta"}
+	translations, err := translateStory(context.Background(), story, "it", []string{"en", "fr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if translations["en"].Name != "ricetta en" || translations["fr"].Name != "ricetta fr" {
+		t.Errorf("unexpected translations %+v", translations)
+	}
+
+	for target, code := range map[string]codes.Code{"xx": codes.InvalidArgument, "zz": codes.ResourceExhausted} {
+		_, err = translateStory(context.Background(), story, "it", []string{"en", target})
+		if st, _ := status.FromError(err); err == nil || st.Code() != code {
+			t.Errorf("expected %s error for %s, got %v", code, target, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// default time allowed to create a job
+const jobTimeout = 30 * time.Second
+
+// createJob creates a job translating the requested stories in background.
+func createJob(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var jReq types.JobRequest
+	err := json.NewDecoder(req.Body).Decode(&jReq)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(conf.Server.JobTimeout, jobTimeout))
+	defer cancel()
+
+	var j job.Job
+	err = callStoryBlok(ctx, "StoryBlok.NewJob", &jReq, &j)
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v1/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+// retranslate creates a job translating the requested stories again, overriding
+// the translations saved already when forced.
+func retranslate(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var rReq types.RetranslateRequest
+	err := json.NewDecoder(req.Body).Decode(&rReq)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(conf.Server.JobTimeout, jobTimeout))
+	defer cancel()
+
+	var j job.Job
+	err = callStoryBlok(ctx, "StoryBlok.Retranslate", &rReq, &j)
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v1/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+// getJob reports the state of each story and language of a job.
+func getJob(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	j, err := job.Get(rdb, ps.ByName("id"))
+	if err == job.ErrNotFound {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, j)
+}
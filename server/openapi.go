@@ -0,0 +1,837 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// openAPI serves the OpenAPI document describing the server routes.
+func openAPI(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(openAPISpec))
+}
+
+// openAPISpec is the OpenAPI 3.0 document of the server API. Keep it in sync
+// with the routes registered in main and with the pkg/client package.
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "polygo",
+    "description": "Translation of Storyblok stories and datasources.",
+    "version": "1.0.0"
+  },
+  "servers": [{"url": "http://localhost:8080"}],
+  "security": [{"bearerAuth": []}, {"apiKey": []}],
+  "paths": {
+    "/v1/translate": {
+      "post": {
+        "summary": "Translate a story synchronously",
+        "description": "Translates a story, sent along or fetched from Storyblok by ID, into each of the target languages. Requires the enqueue scope.",
+        "operationId": "translate",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TranslateRequest"}}}
+        },
+        "responses": {
+          "200": {
+            "description": "Translations by language.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TranslateResponse"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "429": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/jobs": {
+      "post": {
+        "summary": "Create a translation job",
+        "description": "Enqueues the stories to be translated into the target languages, tracking their progress. Requires the enqueue scope.",
+        "operationId": "createJob",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/JobRequest"}}}
+        },
+        "responses": {
+          "202": {
+            "description": "Job created.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "422": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/jobs/{id}": {
+      "get": {
+        "summary": "Get a translation job",
+        "description": "Reports the state of each story and language of the job. Requires the read scope.",
+        "operationId": "getJob",
+        "parameters": [{"$ref": "#/components/parameters/JobID"}],
+        "responses": {
+          "200": {
+            "description": "Job state.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}
+          },
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/retranslate": {
+      "post": {
+        "summary": "Translate stories again",
+        "description": "Creates a job translating the stories into the languages again. With force the translations saved already are overridden, otherwise the stories are saved only in the languages they miss. Requires the enqueue scope.",
+        "operationId": "retranslate",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/RetranslateRequest"}}}
+        },
+        "responses": {
+          "202": {
+            "description": "Job created.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "422": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/xliff": {
+      "get": {
+        "summary": "Export stories as XLIFF",
+        "description": "XLIFF document of the translatable fields of the stories, one unit per field identified as story ID, component UID and field (e.g. 42:<uid>:title). Requires the read scope.",
+        "operationId": "exportXLIFF",
+        "parameters": [
+          {"name": "story_ids", "in": "query", "required": true, "description": "Comma separated story IDs.", "schema": {"type": "string"}},
+          {"name": "lang", "in": "query", "required": true, "description": "Target language.", "schema": {"type": "string"}},
+          {"name": "version", "in": "query", "schema": {"type": "string", "enum": ["1.2", "2.0"], "default": "1.2"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "XLIFF document.",
+            "content": {"application/xliff+xml": {"schema": {"type": "string"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "post": {
+        "summary": "Import translated XLIFF",
+        "description": "Applies the targets of the XLIFF 1.2 or 2.0 document to the current stories and enqueues them to be saved on Storyblok in the target language, overriding the translations saved already. Every translatable field of the stories must have a unit with a target, translated from the current source text. Requires the enqueue scope.",
+        "operationId": "importXLIFF",
+        "requestBody": {
+          "required": true,
+          "content": {"application/xliff+xml": {"schema": {"type": "string"}}}
+        },
+        "responses": {
+          "202": {
+            "description": "Translations enqueued.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"message_ids": {"type": "array", "items": {"type": "string"}}}
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "413": {"$ref": "#/components/responses/Error"},
+          "422": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/po": {
+      "get": {
+        "summary": "Export stories as gettext PO",
+        "description": "PO file of the translatable fields of the stories, one entry per field with story ID, component UID and field as msgctxt (e.g. 42:<uid>:title). Requires the read scope.",
+        "operationId": "exportPO",
+        "parameters": [
+          {"name": "story_ids", "in": "query", "required": true, "description": "Comma separated story IDs.", "schema": {"type": "string"}},
+          {"name": "lang", "in": "query", "required": true, "description": "Target language.", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "PO file.",
+            "content": {"text/x-gettext-translation": {"schema": {"type": "string"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "post": {
+        "summary": "Import translated gettext PO",
+        "description": "Applies the targets of the gettext PO document to the current stories and enqueues them to be saved on Storyblok in the target language, overriding the translations saved already. Every translatable field of the stories must have a unit with a target, translated from the current source text. Requires the enqueue scope.",
+        "operationId": "importPO",
+        "requestBody": {
+          "required": true,
+          "content": {"text/x-gettext-translation": {"schema": {"type": "string"}}}
+        },
+        "responses": {
+          "202": {
+            "description": "Translations enqueued.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"message_ids": {"type": "array", "items": {"type": "string"}}}
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "413": {"$ref": "#/components/responses/Error"},
+          "422": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/csv": {
+      "get": {
+        "summary": "Export stories as CSV",
+        "description": "CSV of the translatable fields of the stories under the header key,<source>,<target>, one row per field keyed by story ID, component UID and field (e.g. 42:<uid>:title). Requires the read scope.",
+        "operationId": "exportCSV",
+        "parameters": [
+          {"name": "story_ids", "in": "query", "required": true, "description": "Comma separated story IDs.", "schema": {"type": "string"}},
+          {"name": "lang", "in": "query", "required": true, "description": "Target language.", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "CSV document.",
+            "content": {"text/csv": {"schema": {"type": "string"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "post": {
+        "summary": "Import translated CSV",
+        "description": "Applies the targets of the CSV document to the current stories and enqueues them to be saved on Storyblok in the target language, overriding the translations saved already. Every translatable field of the stories must have a unit with a target, translated from the current source text. Requires the enqueue scope.",
+        "operationId": "importCSV",
+        "requestBody": {
+          "required": true,
+          "content": {"text/csv": {"schema": {"type": "string"}}}
+        },
+        "responses": {
+          "202": {
+            "description": "Translations enqueued.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"message_ids": {"type": "array", "items": {"type": "string"}}}
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "413": {"$ref": "#/components/responses/Error"},
+          "422": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/usage": {
+      "get": {
+        "summary": "Characters sent to the translation backends",
+        "description": "Characters sent to the translation backends by day, backend and language pair, by story as well with by=story. Requires the read scope.",
+        "operationId": "getUsage",
+        "parameters": [
+          {"name": "from", "in": "query", "description": "First day, the first day of the current month by default.", "schema": {"type": "string", "format": "date"}},
+          {"name": "to", "in": "query", "description": "Last day, today by default. At most 366 days can be requested.", "schema": {"type": "string", "format": "date"}},
+          {"name": "by", "in": "query", "schema": {"type": "string", "enum": ["pair", "story"], "default": "pair"}},
+          {"name": "story_id", "in": "query", "description": "Counts of the story only.", "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "Usage counts.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {
+                "from": {"type": "string", "format": "date"},
+                "to": {"type": "string", "format": "date"},
+                "total": {"type": "integer"},
+                "counts": {"type": "array", "items": {"$ref": "#/components/schemas/UsageCount"}}
+              }
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/jobs/{id}/events": {
+      "get": {
+        "summary": "Stream the events of a translation job",
+        "description": "Server-Sent Events stream of the state changes of the job. Requires the read scope.",
+        "operationId": "jobEvents",
+        "parameters": [
+          {"$ref": "#/components/parameters/JobID"},
+          {"name": "Last-Event-ID", "in": "header", "description": "ID of the last event received, to resume the stream.", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "Stream of job events, the data of each event is a JobEvent.",
+            "content": {"text/event-stream": {"schema": {"type": "string"}}}
+          },
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/stream/stories": {
+      "post": {
+        "summary": "Enqueue stories",
+        "description": "Puts the stories on the translation stream. The body is a single story, an array of stories or a Storyblok export. Requires the enqueue scope.",
+        "operationId": "enqueueStories",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "oneOf": [
+              {"$ref": "#/components/schemas/Story"},
+              {"type": "array", "items": {"$ref": "#/components/schemas/Story"}},
+              {"type": "object", "properties": {"stories": {"type": "array", "items": {"$ref": "#/components/schemas/Story"}}}},
+              {"type": "object", "properties": {"story": {"$ref": "#/components/schemas/Story"}}}
+            ]
+          }}}
+        },
+        "responses": {
+          "202": {
+            "description": "Stories enqueued.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"message_ids": {"type": "array", "items": {"type": "string"}}}
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "413": {"$ref": "#/components/responses/Error"},
+          "422": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/rpc/stories": {
+      "post": {
+        "summary": "Enqueue the new stories of Storyblok",
+        "description": "Fetches the stories published since the last run and enqueues them. Requires the enqueue scope.",
+        "operationId": "newStories",
+        "responses": {
+          "200": {
+            "description": "Stories enqueued.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"stories": {"type": "array", "items": {"$ref": "#/components/schemas/Story"}}}
+            }}}
+          }
+        }
+      }
+    },
+    "/rpc/datasources": {
+      "post": {
+        "summary": "Enqueue datasource entries",
+        "description": "Enqueues the entries of the datasources missing a translation. Requires the enqueue scope.",
+        "operationId": "datasources",
+        "requestBody": {
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "properties": {"datasources": {"type": "array", "items": {"type": "string"}, "description": "Datasource slugs, all when empty."}}
+          }}}
+        },
+        "responses": {
+          "202": {
+            "description": "Entries enqueued.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"entries": {"type": "array", "items": {"$ref": "#/components/schemas/DatasourceEntry"}}}
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/rpc/backfill": {
+      "post": {
+        "summary": "Start a backfill",
+        "description": "Starts or resumes a job enqueueing all the stories missing a language. Requires the admin scope.",
+        "operationId": "startBackfill",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/BackfillRequest"}}}
+        },
+        "responses": {
+          "202": {
+            "description": "Backfill started.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Backfill"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/rpc/backfill/{id}": {
+      "get": {
+        "summary": "Get a backfill",
+        "description": "Reports the progress of a backfill job. Requires the read scope.",
+        "operationId": "backfillProgress",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {
+            "description": "Backfill progress.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Backfill"}}}
+          },
+          "404": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/dry-run": {
+      "get": {
+        "summary": "List dry-run diffs",
+        "description": "Lists the translations recorded instead of being saved while storyblok runs in dry-run mode, newest first. Requires the read scope.",
+        "operationId": "listDiffs",
+        "parameters": [
+          {"name": "story_id", "in": "query", "schema": {"type": "integer"}},
+          {"name": "lang", "in": "query", "schema": {"type": "string"}},
+          {"name": "count", "in": "query", "schema": {"type": "integer", "default": 100}}
+        ],
+        "responses": {
+          "200": {
+            "description": "Diffs.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"diffs": {"type": "array", "items": {"$ref": "#/components/schemas/Diff"}}}
+            }}}
+          },
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/dry-run/{id}": {
+      "get": {
+        "summary": "Get a dry-run diff",
+        "description": "Requires the read scope.",
+        "operationId": "getDiff",
+        "parameters": [{"name": "id", "in": "path", "required": true, "description": "ID of the message on the dry_run stream.", "schema": {"type": "string"}}],
+        "responses": {
+          "200": {
+            "description": "Diff.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Diff"}}}
+          },
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/review": {
+      "get": {
+        "summary": "List review items",
+        "description": "Lists the translations waiting for review, oldest first. Requires the read scope.",
+        "operationId": "listReview",
+        "parameters": [{"name": "status", "in": "query", "schema": {"type": "string", "enum": ["pending", "rejected"], "default": "pending"}}],
+        "responses": {
+          "200": {
+            "description": "Review items.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"items": {"type": "array", "items": {"$ref": "#/components/schemas/ReviewItem"}}}
+            }}}
+          }
+        }
+      }
+    },
+    "/review/{id}": {
+      "parameters": [{"$ref": "#/components/parameters/ReviewID"}],
+      "get": {
+        "summary": "Get a review item",
+        "description": "Requires the read scope.",
+        "operationId": "getReview",
+        "responses": {
+          "200": {
+            "description": "Review item.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ReviewItem"}}}
+          },
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "put": {
+        "summary": "Edit a review item",
+        "description": "Replaces the translation of a pending item. Requires the enqueue scope.",
+        "operationId": "editReview",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "properties": {"story": {"$ref": "#/components/schemas/Story"}}
+          }}}
+        },
+        "responses": {
+          "200": {
+            "description": "Review item edited.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ReviewItem"}}}
+          },
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/review/{id}/approve": {
+      "post": {
+        "summary": "Approve a review item",
+        "description": "Sends the translation to be saved on Storyblok. Edited translations are translated again into the languages translated from the item language, overriding their translations. Requires the enqueue scope.",
+        "operationId": "approveReview",
+        "parameters": [{"$ref": "#/components/parameters/ReviewID"}],
+        "responses": {
+          "202": {"$ref": "#/components/responses/MessageID"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/review/{id}/reject": {
+      "post": {
+        "summary": "Reject a review item",
+        "description": "Sends the story back to be translated again into the item language only. The note is kept on the item. Requires the enqueue scope.",
+        "operationId": "rejectReview",
+        "parameters": [{"$ref": "#/components/parameters/ReviewID"}],
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "required": ["note"],
+            "properties": {"note": {"type": "string"}}
+          }}}
+        },
+        "responses": {
+          "202": {"$ref": "#/components/responses/MessageID"},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/webhooks/storyblok/task": {
+      "post": {
+        "summary": "Storyblok task webhook",
+        "description": "Enqueues the new stories of Storyblok. The body must be signed with the webhook secret.",
+        "operationId": "storyblokTask",
+        "security": [],
+        "parameters": [{"name": "webhook-signature", "in": "header", "required": true, "description": "Hex encoded HMAC-SHA1 of the body.", "schema": {"type": "string"}}],
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"type": "object"}}}
+        },
+        "responses": {
+          "202": {
+            "description": "Stories enqueued.",
+            "content": {"application/json": {"schema": {
+              "type": "object",
+              "properties": {"stories": {"type": "integer"}}
+            }}}
+          },
+          "401": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/healthz": {
+      "get": {
+        "summary": "Liveness probe",
+        "operationId": "healthz",
+        "security": [],
+        "responses": {
+          "200": {"description": "The process is alive.", "content": {"application/json": {"schema": {"type": "object", "properties": {"status": {"type": "string"}}}}}}
+        }
+      }
+    },
+    "/readyz": {
+      "get": {
+        "summary": "Readiness probe",
+        "description": "Checks redis and the grpc services of storyblok and translator.",
+        "operationId": "readyz",
+        "security": [],
+        "responses": {
+          "200": {"description": "All checks passed.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/HealthReport"}}}},
+          "503": {"description": "Some check failed.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/HealthReport"}}}}
+        }
+      }
+    },
+    "/openapi.json": {
+      "get": {
+        "summary": "This document",
+        "operationId": "openAPI",
+        "security": [],
+        "responses": {
+          "200": {"description": "OpenAPI document.", "content": {"application/json": {}}}
+        }
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "bearerAuth": {"type": "http", "scheme": "bearer"},
+      "apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"}
+    },
+    "parameters": {
+      "JobID": {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+      "ReviewID": {"name": "id", "in": "path", "required": true, "description": "Story ID and language, e.g. 123:en.", "schema": {"type": "string"}}
+    },
+    "responses": {
+      "Error": {
+        "description": "Error.",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+      },
+      "MessageID": {
+        "description": "Stream message sent.",
+        "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"message_id": {"type": "string"}}
+        }}}
+      }
+    },
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "properties": {"error": {"type": "string"}}
+      },
+      "Story": {
+        "type": "object",
+        "description": "Storyblok story.",
+        "required": ["id", "content"],
+        "properties": {
+          "id": {"type": "integer"},
+          "name": {"type": "string"},
+          "slug": {"type": "string"},
+          "full_slug": {"type": "string"},
+          "lang": {"type": "string"},
+          "content": {
+            "type": "object",
+            "required": ["component"],
+            "properties": {"component": {"type": "string"}},
+            "additionalProperties": true
+          }
+        },
+        "additionalProperties": true
+      },
+      "TranslateRequest": {
+        "type": "object",
+        "required": ["targets"],
+        "properties": {
+          "story": {"$ref": "#/components/schemas/Story"},
+          "story_id": {"type": "integer", "description": "Storyblok story to translate when no story is sent."},
+          "source": {"type": "string", "description": "Source language code, detected when empty."},
+          "targets": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "TranslateResponse": {
+        "type": "object",
+        "properties": {
+          "translations": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/Story"}}
+        }
+      },
+      "JobRequest": {
+        "type": "object",
+        "required": ["story_ids", "targets"],
+        "properties": {
+          "story_ids": {"type": "array", "items": {"type": "integer"}},
+          "targets": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "RetranslateRequest": {
+        "type": "object",
+        "required": ["story_ids", "langs"],
+        "properties": {
+          "story_ids": {"type": "array", "items": {"type": "integer"}},
+          "langs": {"type": "array", "items": {"type": "string"}},
+          "force": {"type": "boolean", "description": "Override the translations saved already."}
+        }
+      },
+      "JobState": {
+        "type": "string",
+        "enum": ["queued", "translating", "translated", "review", "saved", "failed", "dry_run"]
+      },
+      "JobStatus": {
+        "type": "object",
+        "properties": {
+          "state": {"$ref": "#/components/schemas/JobState"},
+          "error": {"type": "string"},
+          "updated_at": {"type": "string", "format": "date-time"}
+        }
+      },
+      "Job": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "targets": {"type": "array", "items": {"type": "string"}},
+          "created_at": {"type": "string", "format": "date-time"},
+          "stories": {
+            "type": "object",
+            "description": "Status of each target language by story ID.",
+            "additionalProperties": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/JobStatus"}}
+          }
+        }
+      },
+      "JobEvent": {
+        "type": "object",
+        "properties": {
+          "job": {"type": "string"},
+          "story_id": {"type": "integer"},
+          "lang": {"type": "string"},
+          "state": {"$ref": "#/components/schemas/JobState"},
+          "error": {"type": "string"},
+          "time": {"type": "string", "format": "date-time"}
+        }
+      },
+      "DatasourceEntry": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "integer"},
+          "name": {"type": "string"},
+          "value": {"type": "string"},
+          "dimension_value": {"type": "string"},
+          "datasource_id": {"type": "integer"}
+        }
+      },
+      "BackfillRequest": {
+        "type": "object",
+        "required": ["id", "lang"],
+        "properties": {
+          "id": {"type": "string", "description": "Submitting the same ID again resumes the job."},
+          "lang": {"type": "string"},
+          "starts_with": {"type": "string"},
+          "query": {"type": "object", "additionalProperties": {"type": "string"}},
+          "rate": {"type": "integer", "maximum": 100, "description": "Max number of stories enqueued per second, 5 by default."}
+        }
+      },
+      "Backfill": {
+        "allOf": [
+          {"$ref": "#/components/schemas/BackfillRequest"},
+          {
+            "type": "object",
+            "properties": {
+              "page": {"type": "integer"},
+              "scanned": {"type": "integer"},
+              "enqueued": {"type": "integer"},
+              "running": {"type": "boolean"},
+              "done": {"type": "boolean"},
+              "error": {"type": "string"},
+              "started_at": {"type": "string", "format": "date-time"},
+              "updated_at": {"type": "string", "format": "date-time"}
+            }
+          }
+        ]
+      },
+      "HealthReport": {
+        "type": "object",
+        "properties": {
+          "status": {"type": "string", "enum": ["ok", "fail"]},
+          "checks": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "object",
+              "properties": {
+                "status": {"type": "string", "enum": ["ok", "fail"]},
+                "error": {"type": "string"},
+                "latency": {"type": "string"}
+              }
+            }
+          }
+        }
+      },
+      "FieldPair": {
+        "type": "object",
+        "properties": {
+          "path": {"type": "string"},
+          "source": {"type": "string"},
+          "target": {"type": "string"}
+        }
+      },
+      "Diff": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "story_id": {"type": "integer"},
+          "lang": {"type": "string"},
+          "job": {"type": "string"},
+          "created_at": {"type": "string", "format": "date-time"},
+          "fields": {"type": "array", "items": {
+            "type": "object",
+            "properties": {
+              "path": {"type": "string"},
+              "source": {"type": "string"},
+              "current": {"type": "string", "description": "Translation saved on Storyblok, empty if missing."},
+              "translation": {"type": "string"},
+              "changed": {"type": "boolean"}
+            }
+          }}
+        }
+      },
+      "UsageCount": {
+        "type": "object",
+        "properties": {
+          "day": {"type": "string", "format": "date"},
+          "backend": {"type": "string", "example": "google"},
+          "pair": {"type": "string", "example": "it-en"},
+          "story_id": {"type": "integer"},
+          "characters": {"type": "integer"}
+        }
+      },
+      "QualityReport": {
+        "type": "object",
+        "description": "Similarity of the fields translated back into the source language with their source text, fields below the threshold being low confidence.",
+        "properties": {
+          "threshold": {"type": "number"},
+          "fields": {"type": "array", "items": {
+            "type": "object",
+            "properties": {
+              "path": {"type": "string"},
+              "score": {"type": "number", "minimum": 0, "maximum": 1},
+              "back_translation": {"type": "string"}
+            }
+          }}
+        }
+      },
+      "ReviewItem": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "story_id": {"type": "integer"},
+          "lang": {"type": "string"},
+          "status": {"type": "string", "enum": ["pending", "rejected"]},
+          "note": {"type": "string"},
+          "job": {"type": "string"},
+          "force": {"type": "boolean"},
+          "quality": {"$ref": "#/components/schemas/QualityReport"},
+          "source": {"$ref": "#/components/schemas/Story"},
+          "target": {"$ref": "#/components/schemas/Story"},
+          "created_at": {"type": "string", "format": "date-time"},
+          "updated_at": {"type": "string", "format": "date-time"},
+          "fields": {"type": "array", "items": {"$ref": "#/components/schemas/FieldPair"}}
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	var spec struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	err := json.Unmarshal([]byte(openAPISpec), &spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/v1/translate", "/v1/jobs", "/v1/jobs/{id}", "/v1/retranslate", "/v1/xliff", "/v1/po", "/v1/csv", "/v1/usage", "/stream/stories", "/dry-run"} {
+		if _, ok := spec.Paths[p]; !ok {
+			t.Errorf("path %s not documented", p)
+		}
+	}
+}
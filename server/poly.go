@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"log"
-	"net"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
-	"net/rpc/jsonrpc"
 	"strings"
+	"time"
 
 	"github.com/go-redis/redis"
 	"github.com/julienschmidt/httprouter"
-	"github.com/spf13/viper"
 
+	"github.com/kind84/polygo/pkg/config"
+	"github.com/kind84/polygo/pkg/health"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/tracing"
 	"github.com/kind84/polygo/pkg/types"
 )
 
@@ -28,166 +34,234 @@ type task struct {
 
 // ---
 
+// configuration and redis client shared by the handlers
+var (
+	conf config.Server
+	rdb  *redis.Client
+)
+
 func init() {
-	log.Println("Setting up configuration...")
-	viper.SetConfigName("config")
-	viper.AddConfigPath(".")
-	viper.SetEnvPrefix("polygo")
-	viper.AutomaticEnv()
-	replacer := strings.NewReplacer(".", "_")
-	viper.SetEnvKeyReplacer(replacer)
-	viper.ReadInConfig()
+	logging.Infof("Setting up configuration...")
+	err := config.Init()
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
 }
 
 func main() {
-	mux := httprouter.New()
-	mux.GET("/", hello)
-	// mux.POST("/translate", translate)
-	// mux.POST("/rpc/translate", rpcTranslate)
-	mux.POST("/rpc/stories", rpcStories)
-	mux.POST("/stream/stories", streamStories)
-
-	port := viper.GetString("server.port")
-	log.Println("Listenting on port " + port)
-	http.ListenAndServe(":"+port, mux)
-}
-
-func hello(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	w.Write([]byte("Hello from polygo\n"))
-}
+	err := config.Load(&conf)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+	logging.Init("polygo-server", conf.Log.ParsedLevel())
+	logging.Infof("Configuration:\n%s", config.Dump(&conf))
 
-// func translate(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-// 	// var task sbTask
+	// export traces when a collector is configured
+	closeTracing, err := tracing.Init("polygo-server", conf.Tracing.Endpoint, conf.Tracing.SampleRatio)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+	defer closeTracing()
 
-// 	// err := json.NewDecoder(req.Body).Decode(&task)
-// 	// if err != nil {
-// 	// 	log.Println(err)
-// 	// }
-// 	ctx := context.Background()
+	rdb = redis.NewClient(&redis.Options{Addr: conf.Redis.Host})
+	defer rdb.Close()
 
-// 	var txt struct {
-// 		Text string `json:"text"`
-// 	}
+	closeServices, err := dialServices(
+		conf.Storyblok.GRPCHost,
+		conf.Translator.GRPCHost,
+	)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+	defer closeServices()
 
-// 	err := json.NewDecoder(req.Body).Decode(&txt)
-// 	if err != nil {
-// 		log.Fatalln(err)
-// 	}
+	auth := newAuthenticator(
+		conf.Server.Auth.Keys,
+		conf.Server.Auth.WebhookSecret,
+	)
 
-// 	sbToken := viper.GetString("storyblok.token")
-// 	sbClient := storyblok.NewClient(sbToken)
-// 	stories, err := sbClient.NewStories()
-// 	if err != nil {
-// 		log.Fatalln(err)
-// 	}
+	hc := health.NewChecker(5 * time.Second)
+	hc.Add("redis", health.Redis(rdb))
+	hc.Add("storyblok", serving(storyblokHealth, "polygo.StoryBlok"))
+	hc.Add("translator", serving(translatorHealth, "polygo.Translator"))
 
-// 	var resp struct {
-// 		Stories []storyblok.Story `json:"stories"`
-// 	}
+	mux := httprouter.New()
+	mux.GET("/", hello)
+	mux.HandlerFunc("GET", "/healthz", hc.Healthz)
+	mux.HandlerFunc("GET", "/readyz", hc.Readyz)
+	mux.Handler("GET", "/metrics", metrics.Handler())
+	mux.GET("/openapi.json", openAPI)
+	mux.POST("/v1/translate", auth.require(scopeEnqueue, translate))
+	mux.POST("/v1/jobs", auth.require(scopeEnqueue, createJob))
+	mux.GET("/v1/jobs/:id", auth.require(scopeRead, getJob))
+	mux.GET("/v1/jobs/:id/events", auth.require(scopeRead, jobEvents))
+	mux.POST("/v1/retranslate", auth.require(scopeEnqueue, retranslate))
+	for _, f := range []catalogFormat{xliffFormat, poFormat, csvFormat} {
+		mux.GET("/v1/"+f.name, auth.require(scopeRead, exportCatalog(f)))
+		mux.POST("/v1/"+f.name, auth.require(scopeEnqueue, importCatalog(f)))
+	}
+	mux.GET("/v1/usage", auth.require(scopeRead, getUsage))
+	mux.POST("/rpc/stories", auth.require(scopeEnqueue, rpcStories))
+	mux.POST("/stream/stories", auth.require(scopeEnqueue, streamStories))
+	mux.POST("/rpc/datasources", auth.require(scopeEnqueue, rpcDatasources))
+	mux.POST("/rpc/backfill", auth.require(scopeAdmin, startBackfill))
+	mux.GET("/rpc/backfill/*id", auth.require(scopeRead, backfillProgress))
+	mux.GET("/dry-run", auth.require(scopeRead, listDiffs))
+	mux.GET("/dry-run/:id", auth.require(scopeRead, getDiff))
+	mux.GET("/review", auth.require(scopeRead, listReview))
+	mux.GET("/review/:id", auth.require(scopeRead, getReview))
+	mux.PUT("/review/:id", auth.require(scopeEnqueue, editReview))
+	mux.POST("/review/:id/approve", auth.require(scopeEnqueue, approveReview))
+	mux.POST("/review/:id/reject", auth.require(scopeEnqueue, rejectReview))
+	mux.POST("/webhooks/storyblok/task", auth.signed(storyblokTask))
+
+	port := conf.Server.Port
+	logging.Infof("Listening on port %s", port)
+	err = http.ListenAndServe(":"+port, tracing.Handler(mux, route(mux), "/healthz", "/readyz", "/metrics"))
+	logging.Fatalf("%s", err)
+}
 
-// 	tChan := make(chan storyblok.Story)
-// 	defer close(tChan)
+// route returns the path template of the route of the router matching the
+// request, such as /v1/jobs/:id, naming the spans of the requests. Requests
+// matching no route are all named "not found".
+func route(mux *httprouter.Router) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		h, ps, _ := mux.Lookup(req.Method, req.URL.Path)
+		if h == nil {
+			return "not found"
+		}
+
+		// catch-all parameter, matching the rest of the path
+		var rest, catchAll string
+		for _, p := range ps {
+			if strings.HasPrefix(p.Value, "/") {
+				rest, catchAll = p.Value, "/*"+p.Key
+			}
+		}
+
+		// a segment is a parameter when another value matches it as well
+		const probe = "\x00"
+		segments := strings.Split(strings.TrimSuffix(req.URL.Path, rest), "/")
+		for i, seg := range segments {
+			if seg == "" {
+				continue
+			}
+			segments[i] = probe
+			_, probed, _ := mux.Lookup(req.Method, strings.Join(segments, "/")+rest)
+			segments[i] = seg
+			for _, p := range probed {
+				if p.Value == probe {
+					segments[i] = ":" + p.Key
+				}
+			}
+		}
+		return strings.Join(segments, "/") + catchAll
+	}
+}
 
-// 	for _, story := range stories {
-// 		// Message with recipe and translation channel
-// 		msg := translator.Message{
-// 			Story:       story,
-// 			Translation: tChan,
-// 		}
+func hello(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	w.Write([]byte("Hello from polygo\n"))
+}
 
-// 		go translator.TranslateRecipe(ctx, msg)
-// 	}
+// writeJSON encodes v as the JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		logging.Errorf("%s", err)
+	}
+}
 
-// 	for range stories {
-// 		t := <-tChan
-// 		resp.Stories = append(resp.Stories, t)
-// 	}
+// writeError sends a JSON error message with the given status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
 
-// 	json.NewEncoder(w).Encode(resp)
-// }
+// max size of the stories sent to be enqueued
+const maxStoriesBody = 10 << 20
 
+// streamStories validates the stories in the request body, either a single story,
+// an array of stories or a Storyblok export, and puts them on the stream to be
+// translated in a single transaction.
 func streamStories(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	rh := viper.GetString("redis.host")
-
-	rdb := redis.NewClient(&redis.Options{Addr: rh})
-
-	msg := map[string]interface{}{"hello": "world"}
-
-	xargs := redis.XAddArgs{
-		Stream: "storyblok",
-		// MaxLen       int64 // MAXLEN N
-		// MaxLenApprox int64 // MAXLEN ~ N
-		// ID           string
-		Values: msg,
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxStoriesBody))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
 	}
 
-	pipe := rdb.Pipeline()
-	xadd := pipe.XAdd(&xargs)
-	_, err := pipe.Exec()
+	ss, err := types.ParseStories(body)
 	if err != nil {
-		log.Fatalln(err)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(ss) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no stories found"))
+		return
+	}
+	for i, story := range ss {
+		err = story.Validate()
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("story %d: %s", i, err))
+			return
+		}
 	}
 
-	log.Println(xadd.Val())
-}
-
-func rpcStories(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	sb := viper.GetString("storyblok.host")
-	conn, err := net.Dial("tcp", sb)
+	xadds := make([]*redis.StringCmd, 0, len(ss))
+	_, err = rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, story := range ss {
+			js, err := json.Marshal(story)
+			if err != nil {
+				return err
+			}
+
+			values := map[string]interface{}{"story": js}
+			tracing.Inject(req.Context(), values)
+
+			xadds = append(xadds, pipe.XAdd(&redis.XAddArgs{
+				Stream: "storyblok",
+				Values: values,
+			}))
+		}
+		return nil
+	})
 	if err != nil {
-		log.Fatalln(err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
 	}
-	defer conn.Close()
 
-	request := types.Request{}
-	reply := types.Reply{
-		ID: "pippo",
+	metrics.StoriesEnqueued.WithLabelValues("api").Add(float64(len(xadds)))
+
+	resp := struct {
+		MessageIDs []string `json:"message_ids"`
+	}{MessageIDs: make([]string, 0, len(xadds))}
+	for i, xadd := range xadds {
+		logging.With(logging.Fields{
+			"stream":     "storyblok",
+			"message_id": xadd.Val(),
+			"story_id":   ss[i].ID,
+		}).Infof("Sending message ID %s for story ID %d", xadd.Val(), ss[i].ID)
+		resp.MessageIDs = append(resp.MessageIDs, xadd.Val())
 	}
 
-	c := jsonrpc.NewClient(conn)
+	writeJSON(w, http.StatusAccepted, resp)
+}
 
-	err = c.Call("StoryBlok.NewStories", &request, &reply)
+// rpcStories sends the new stories of Storyblok to be translated.
+func rpcStories(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(conf.Server.JobTimeout, jobTimeout))
+	defer cancel()
+
+	ss, err := newStories(ctx)
 	if err != nil {
-		log.Fatalln(err)
+		writeRPCError(w, err)
+		return
 	}
 
 	resp := struct {
 		Stories []types.Story `json:"stories"`
-	}{Stories: reply.Stories}
+	}{Stories: ss}
 
-	json.NewEncoder(w).Encode(resp)
+	writeJSON(w, http.StatusOK, resp)
 }
-
-// func rpcTranslate(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-// 	conn, err := net.Dial("tcp", "localhost:8090")
-// 	if err != nil {
-// 		log.Fatalln(err)
-// 	}
-// 	defer conn.Close()
-
-// 	sbToken := viper.GetString("storyblok.token")
-// 	sbClient := storyblok.NewClient(sbToken)
-// 	stories, err := sbClient.NewStories()
-// 	if err != nil {
-// 		log.Fatalln(err)
-// 	}
-
-// 	var resp struct {
-// 		Stories []storyblok.Story `json:"stories"`
-// 	}
-
-// 	c := jsonrpc.NewClient(conn)
-
-// 	for _, story := range stories {
-// 		reply := translator.Reply{ID: story.ID}
-// 		request := translator.Request{Story: story}
-
-// 		err = c.Call("Translator.Translate", &request, &reply)
-// 		if err != nil {
-// 			log.Fatalln(err)
-// 		}
-// 		resp.Stories = append(resp.Stories, reply.Translation)
-// 	}
-
-// 	json.NewEncoder(w).Encode(resp)
-// }
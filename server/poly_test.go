@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestRoute(t *testing.T) {
+	mux := httprouter.New()
+	mux.GET("/v1/jobs/:id", ok)
+	mux.GET("/v1/jobs/:id/events", ok)
+	mux.POST("/review/:id/approve", ok)
+	mux.GET("/rpc/backfill/*id", ok)
+	name := route(mux)
+
+	tests := map[string]string{
+		"GET /v1/jobs/abc":                  "/v1/jobs/:id",
+		"GET /v1/jobs/abc/events":           "/v1/jobs/:id/events",
+		"POST /review/review/approve":       "/review/:id/approve",
+		"GET /rpc/backfill/en:recipes/2020": "/rpc/backfill/*id",
+		"GET /v1/missing":                   "not found",
+	}
+	for req, want := range tests {
+		parts := strings.SplitN(req, " ", 2)
+		if got := name(httptest.NewRequest(parts[0], parts[1], nil)); got != want {
+			t.Errorf("route of %s: got %q, want %q", req, got, want)
+		}
+	}
+}
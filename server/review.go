@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/review"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// reviewItem is an item of the review queue along with its fields side by side.
+type reviewItem struct {
+	*review.Item
+	Fields []review.FieldPair `json:"fields"`
+}
+
+func newReviewItem(item *review.Item) reviewItem {
+	return reviewItem{
+		Item:   item,
+		Fields: item.Fields(),
+	}
+}
+
+// listReview lists the review items with the status given in the query (pending by default).
+func listReview(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	status := review.Status(req.URL.Query().Get("status"))
+	if status == "" {
+		status = review.Pending
+	}
+
+	q := review.NewQueue(rdb)
+	items, err := q.List(status)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := struct {
+		Items []reviewItem `json:"items"`
+	}{Items: make([]reviewItem, 0, len(items))}
+	for i := range items {
+		resp.Items = append(resp.Items, newReviewItem(&items[i]))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func getReview(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	q := review.NewQueue(rdb)
+	item, err := q.Get(ps.ByName("id"))
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newReviewItem(item))
+}
+
+// editReview replaces the translation of a pending item with the story in the request body.
+func editReview(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var body struct {
+		Story types.Story `json:"story"`
+	}
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	q := review.NewQueue(rdb)
+	item, err := q.Edit(ps.ByName("id"), body.Story)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newReviewItem(item))
+}
+
+// approveReview forwards the translation of a pending item to be saved on Storyblok.
+func approveReview(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	q := review.NewQueue(rdb)
+	id, err := q.Approve(ps.ByName("id"))
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"message_id": id})
+}
+
+// rejectReview sends a pending item back to translation with the note given in the request body.
+func rejectReview(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var body struct {
+		Note string `json:"note"`
+	}
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if body.Note == "" {
+		writeError(w, http.StatusBadRequest, errors.New("a note is required to reject a translation"))
+		return
+	}
+
+	q := review.NewQueue(rdb)
+	id, err := q.Reject(ps.ByName("id"), body.Note)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"message_id": id})
+}
+
+func writeReviewError(w http.ResponseWriter, err error) {
+	switch err {
+	case review.ErrNotFound:
+		writeError(w, http.StatusNotFound, err)
+	case review.ErrNotPending:
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
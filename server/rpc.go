@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kind84/polygo/pkg/tracing"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// callStoryBlok calls a method of the storyblok jsonrpc server.
+func callStoryBlok(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	return callRPC(ctx, conf.Storyblok.Host, method, args, reply)
+}
+
+// callRPC calls a jsonrpc method on the given host, giving up once the context is done.
+func callRPC(ctx context.Context, host string, method string, args interface{}, reply interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "jsonrpc "+method)
+	defer func() { tracing.End(ctx, span, err) }()
+
+	// send the trace context along with the requests supporting it
+	if t, ok := args.(interface{ SetTrace(types.Trace) }); ok {
+		trace := types.Trace{}
+		tracing.InjectMap(ctx, trace)
+		t.SetTrace(trace)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c := jsonrpc.NewClient(conn)
+	call := c.Go(method, args, reply, make(chan *rpc.Call, 1))
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-call.Done:
+		return rpcStatus(call.Error)
+	}
+}
+
+// rpcStatus rebuilds the grpc status returned by a jsonrpc method, which only
+// carries the error message, so that its code is mapped to the response.
+func rpcStatus(err error) error {
+	se, ok := err.(rpc.ServerError)
+	if !ok {
+		return err
+	}
+	return statusError(string(se))
+}
+
+// statusError rebuilds the grpc status from its error message, a plain error
+// when the message does not come from a status.
+func statusError(msg string) error {
+	const prefix = "rpc error: code = "
+	if !strings.HasPrefix(msg, prefix) {
+		return errors.New(msg)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(msg, prefix), " desc = ", 2)
+	if len(parts) != 2 {
+		return errors.New(msg)
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == parts[0] {
+			return status.Error(c, parts[1])
+		}
+	}
+	return errors.New(msg)
+}
+
+// writeRPCError maps a jsonrpc or grpc call error to the response status code.
+func writeRPCError(w http.ResponseWriter, err error) {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.DeadlineExceeded:
+			writeError(w, http.StatusGatewayTimeout, errors.New(st.Message()))
+		case codes.InvalidArgument:
+			writeError(w, http.StatusBadRequest, errors.New(st.Message()))
+		case codes.FailedPrecondition:
+			writeError(w, http.StatusUnprocessableEntity, errors.New(st.Message()))
+		case codes.NotFound:
+			writeError(w, http.StatusNotFound, errors.New(st.Message()))
+		case codes.ResourceExhausted:
+			writeError(w, http.StatusTooManyRequests, errors.New(st.Message()))
+		default:
+			writeError(w, http.StatusBadGateway, errors.New(st.Message()))
+		}
+		return
+	}
+	if ne, ok := err.(net.Error); (ok && ne.Timeout()) || err == context.DeadlineExceeded {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	}
+	writeError(w, http.StatusBadGateway, err)
+}
+
+// requestTimeout returns the configured timeout, or def when not configured.
+func requestTimeout(d time.Duration, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
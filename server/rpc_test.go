@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWriteRPCError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"jsonrpc invalid argument", rpc.ServerError(status.Error(codes.InvalidArgument, "missing backfill language").Error()), http.StatusBadRequest},
+		{"jsonrpc failed precondition", rpc.ServerError(status.Error(codes.FailedPrecondition, "language xx is not a target language").Error()), http.StatusUnprocessableEntity},
+		{"jsonrpc not found", rpc.ServerError(status.Error(codes.NotFound, "backfill en: not found").Error()), http.StatusNotFound},
+		{"jsonrpc upstream failure", rpc.ServerError("storyblok replied 500 Internal Server Error"), http.StatusBadGateway},
+		{"grpc resource exhausted", status.Error(codes.ResourceExhausted, "budget used up"), http.StatusTooManyRequests},
+		{"grpc deadline", status.Error(codes.DeadlineExceeded, "too slow"), http.StatusGatewayTimeout},
+		{"connection refused", errors.New("connection refused"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		writeRPCError(w, rpcStatus(tt.err))
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	st, _ := status.FromError(rpcStatus(rpc.ServerError(status.Error(codes.InvalidArgument, "bad = input").Error())))
+	if st.Message() != "bad = input" {
+		t.Errorf("expected the message kept, got %q", st.Message())
+	}
+}
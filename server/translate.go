@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/text/language"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// default time allowed to translate a story on demand
+const translateTimeout = 60 * time.Second
+
+// translate translates a story into each of the requested languages.
+func translate(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var tReq types.TranslateStoryRequest
+	err := json.NewDecoder(req.Body).Decode(&tReq)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = validateTranslate(&tReq)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(conf.Server.TranslateTimeout, translateTimeout))
+	defer cancel()
+
+	var story types.Story
+	if tReq.Story != nil {
+		story = *tReq.Story
+	} else {
+		story, err = getStory(ctx, tReq.StoryID)
+		if err != nil {
+			writeRPCError(w, err)
+			return
+		}
+	}
+
+	translations, err := translateStory(ctx, story, tReq.Source, tReq.Targets)
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	resp := types.TranslateStoryReply{Translations: translations}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func validateTranslate(r *types.TranslateStoryRequest) error {
+	if r.Story == nil && r.StoryID == 0 {
+		return errors.New("either story or story_id is required")
+	}
+	if len(r.Targets) == 0 {
+		return errors.New("at least one target language is required")
+	}
+	if r.Source != "" {
+		if _, err := language.Parse(r.Source); err != nil {
+			return err
+		}
+	}
+	for _, t := range r.Targets {
+		if _, err := language.Parse(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
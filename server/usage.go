@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/usage"
+)
+
+// max days of usage returned by a request
+const maxUsageDays = 366
+
+// getUsage returns the characters sent to the translation backends by day,
+// backend and language pair, by story as well when by=story.
+func getUsage(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	q, err := usageQuery(req.URL.Query(), time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	counts, err := usage.List(rdb, q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var total int64
+	for _, c := range counts {
+		total += c.Characters
+	}
+
+	resp := struct {
+		From   string        `json:"from"`
+		To     string        `json:"to"`
+		Total  int64         `json:"total"`
+		Counts []usage.Count `json:"counts"`
+	}{
+		From:   q.From.Format("2006-01-02"),
+		To:     q.To.Format("2006-01-02"),
+		Total:  total,
+		Counts: counts,
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// usageQuery reads the days from and to, from the first day of the month to
+// today by default, and the story_id and by parameters of the query.
+func usageQuery(values url.Values, now time.Time) (usage.Query, error) {
+	now = now.UTC()
+	q := usage.Query{
+		From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+
+	var err error
+	if v := values.Get("from"); v != "" {
+		q.From, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, errors.New("from must be a day as 2006-01-02")
+		}
+	}
+	if v := values.Get("to"); v != "" {
+		q.To, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, errors.New("to must be a day as 2006-01-02")
+		}
+	}
+	if q.To.Before(q.From) {
+		return q, errors.New("to must not be before from")
+	}
+	if q.To.Sub(q.From) >= maxUsageDays*24*time.Hour {
+		return q, errors.New("at most 366 days of usage can be requested")
+	}
+
+	if v := values.Get("story_id"); v != "" {
+		q.StoryID, err = strconv.Atoi(v)
+		if err != nil {
+			return q, errors.New("story_id must be a story ID")
+		}
+	}
+	switch values.Get("by") {
+	case "", "pair":
+	case "story":
+		q.Stories = true
+	default:
+		return q, errors.New("by must be pair or story")
+	}
+	return q, nil
+}
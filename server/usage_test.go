@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestUsageQuery(t *testing.T) {
+	now := time.Date(2020, 3, 18, 15, 4, 5, 0, time.UTC)
+
+	q, err := usageQuery(url.Values{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.From.Format("2006-01-02") != "2020-03-01" || q.To.Format("2006-01-02") != "2020-03-18" {
+		t.Errorf("expected the current month, got %s to %s", q.From, q.To)
+	}
+
+	q, err = usageQuery(url.Values{"from": {"2020-02-10"}, "to": {"2020-02-20"}, "by": {"story"}, "story_id": {"42"}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Stories || q.StoryID != 42 || q.From.Day() != 10 || q.To.Day() != 20 {
+		t.Errorf("unexpected query %+v", q)
+	}
+
+	for _, invalid := range []url.Values{
+		{"from": {"10/02/2020"}},
+		{"from": {"2020-02-20"}, "to": {"2020-02-10"}},
+		{"from": {"2018-01-01"}, "to": {"2020-01-01"}},
+		{"story_id": {"abc"}},
+		{"by": {"week"}},
+	} {
+		if _, err := usageQuery(invalid, now); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
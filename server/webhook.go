@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+// storyblokTask handles the Storyblok task webhook, sending the new stories to be translated.
+func storyblokTask(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var task sbTask
+	err := json.NewDecoder(req.Body).Decode(&task)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	logging.Infof("Task %s triggered on space %d", task.Task.Name, task.SpaceID)
+
+	ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(conf.Server.JobTimeout, jobTimeout))
+	defer cancel()
+
+	ss, err := newStories(ctx)
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]int{"stories": len(ss)})
+}
@@ -2,20 +2,30 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"net"
+	"net/http"
 	"net/rpc"
 	"net/rpc/jsonrpc"
 	"os"
 	"os/signal"
 	"runtime"
-	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-redis/redis"
-	"github.com/spf13/viper"
-
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kind84/polygo/pkg/catalog"
+	"github.com/kind84/polygo/pkg/config"
+	"github.com/kind84/polygo/pkg/health"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/pb"
+	"github.com/kind84/polygo/pkg/review"
+	"github.com/kind84/polygo/pkg/tracing"
 	"github.com/kind84/polygo/storyblok/storyblok"
 )
 
@@ -25,29 +35,97 @@ func startServer(rdb *redis.Client, s *storyblok.StoryBlok) {
 
 	l, err := net.Listen("tcp", ":8070")
 	if err != nil {
-		log.Fatalln("listen error:", err)
+		logging.Fatalf("listen error: %s", err)
 	}
 
-	log.Println("Jsonrpc server listening on port 8070")
+	logging.Infof("Jsonrpc server listening on port 8070")
 	for {
 		conn, err := l.Accept()
 		if err != nil {
-			log.Fatalln(err)
+			logging.Fatalf("%s", err)
 		}
 
 		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
 	}
 }
 
+// startGRPCServer serves the StoryBlok methods over grpc, along with the health checks.
+func startGRPCServer(gs *grpc.Server, hs *grpchealth.Server, g *storyblok.GRPCServer) {
+	pb.RegisterStoryBlokServer(gs, g)
+	healthpb.RegisterHealthServer(gs, hs)
+	hs.SetServingStatus("polygo.StoryBlok", healthpb.HealthCheckResponse_SERVING)
+
+	l, err := net.Listen("tcp", ":8071")
+	if err != nil {
+		logging.Fatalf("listen error: %s", err)
+	}
+
+	logging.Infof("gRPC server listening on port 8071")
+	err = gs.Serve(l)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+}
+
+// startHTTPServer serves the health probes and the metrics.
+func startHTTPServer(hc *health.Checker) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", hc.Healthz)
+	mux.HandleFunc("/readyz", hc.Readyz)
+	mux.Handle("/metrics", metrics.Handler())
+
+	logging.Infof("HTTP server listening on port 8072")
+	err := http.ListenAndServe(":8072", mux)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+}
+
+// streams returns the stream data of the translations into each language,
+// along with the stream of the approved translations when reviews are enabled.
+func streams(conf *config.Storyblok, withReview bool) []storyblok.StreamData {
+	var ss []storyblok.StreamData
+	for _, code := range conf.Storyblok.Languages {
+		ss = append(ss, storyblok.StreamData{
+			Stream:   "translation_" + code,
+			Group:    "storybloks_" + code,
+			Consumer: "storybloker_" + code,
+			Code:     code,
+		})
+	}
+	if withReview {
+		ss = append(ss, storyblok.StreamData{
+			Stream:   review.ApprovedStream,
+			Group:    "storybloks_review",
+			Consumer: "storybloker_review",
+			Reviewed: true,
+		})
+	}
+	// translations made by professional translators through XLIFF, PO and CSV documents
+	ss = append(ss, storyblok.StreamData{
+		Stream:   catalog.ImportStream,
+		Group:    "storybloks_import",
+		Consumer: "storybloker_import",
+		Reviewed: true,
+	})
+	return ss
+}
+
+// targets returns the target languages by content type, all the translated
+// languages by default.
+func targets(conf *config.Storyblok) storyblok.Targets {
+	if len(conf.Storyblok.Targets) > 0 {
+		return storyblok.Targets(conf.Storyblok.Targets)
+	}
+	return storyblok.Targets{storyblok.AnyComponent: conf.Storyblok.Languages}
+}
+
 func init() {
-	log.Println("Setting up configuration...")
-	viper.SetConfigName("config")
-	viper.AddConfigPath(".")
-	viper.SetEnvPrefix("polygo")
-	viper.AutomaticEnv()
-	replacer := strings.NewReplacer(".", "_")
-	viper.SetEnvKeyReplacer(replacer)
-	viper.ReadInConfig()
+	logging.Infof("Setting up configuration...")
+	err := config.Init()
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
 }
 
 func main() {
@@ -55,50 +133,115 @@ func main() {
 
 	// Wire shutdownCh to get events depending on the OS we are running in
 	if runtime.GOOS == "windows" {
-		fmt.Println("Listening to Windows OS interrupt signal for graceful shutdown.")
+		logging.Infof("Listening to Windows OS interrupt signal for graceful shutdown.")
 		signal.Notify(shutdownCh, os.Interrupt)
 
 	} else {
-		fmt.Println("Listening to SIGINT or SIGTERM for graceful shutdown.")
+		logging.Infof("Listening to SIGINT or SIGTERM for graceful shutdown.")
 		signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
 	}
 
 	ctx := context.Background()
 
-	rh := viper.GetString("redis.host")
-	rdb := redis.NewClient(&redis.Options{Addr: rh})
+	var conf config.Storyblok
+	err := config.Load(&conf)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+	logging.Init("polygo-storyblok", conf.Log.ParsedLevel())
+	logging.Infof("Configuration:\n%s", config.Dump(&conf))
 
-	token := viper.GetString("storyblok.token")
-	oauth := viper.GetString("storyblok.oauth")
-	space := viper.GetString("storyblok.space")
+	// export traces when a collector is configured
+	closeTracing, err := tracing.Init("polygo-storyblok", conf.Tracing.Endpoint, conf.Tracing.SampleRatio)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+	defer closeTracing()
 
-	s := storyblok.NewSBClient(token, oauth, space, rdb)
+	rdb := redis.NewClient(&redis.Options{Addr: conf.Redis.Host})
+
+	s := storyblok.NewSBClient(conf.Storyblok.Token, conf.Storyblok.OAuth, conf.Storyblok.Space, targets(&conf), rdb)
 
 	go startServer(rdb, s)
 
-	streams := []storyblok.StreamData{
-		{
-			Stream:   "translation_en",
-			Group:    "storybloks_en",
-			Consumer: "storybloker_en",
-			Code:     "en",
-		},
-		{
-			Stream:   "translation_fr",
-			Group:    "storybloks_fr",
-			Consumer: "storybloker_fr",
-			Code:     "fr",
-		},
+	// resume the backfill jobs interrupted by a restart
+	err = s.ResumeBackfills()
+	if err != nil {
+		logging.Errorf("%s", err)
+	}
+
+	// pick up again the stories translated before a target language was added
+	if conf.Storyblok.ResetTranslated && conf.Storyblok.DryRun {
+		logging.Warnf("Dry run, translated flags not reset")
+	} else if conf.Storyblok.ResetTranslated {
+		go func() {
+			n, err := s.ResetTranslated()
+			if err != nil {
+				logging.Errorf("Translated flag reset for %d stories, then failed: %s", n, err)
+				return
+			}
+			logging.Infof("Translated flag reset for %d stories", n)
+		}()
 	}
 
-	sc := storyblok.NewSBConsumer(s)
+	// hold translations for human review before saving them
+	var rq *review.Queue
+	if conf.Review.Enabled {
+		logging.Infof("Translations review enabled.")
+		rq = review.NewQueue(rdb)
+	}
 
-	go sc.ReadTranslation(ctx, streams)
+	sc := storyblok.NewSBConsumer(s, rq)
+	sc.SetSaveInterval(conf.Storyblok.SaveInterval)
+	sc.SetDryRun(conf.Storyblok.DryRun)
+	sc.SetLowConfidenceOnly(conf.Review.LowConfidenceOnly)
+
+	ss := streams(&conf, rq != nil)
+	sc.ReadTranslation(ctx, ss)
+	groups := health.NewGroupList(consumerGroups(ss))
+
+	// apply the changes of the config file without restarting, the review
+	// queue is set at startup only
+	config.Watch(&conf, func(next interface{}) {
+		nc := next.(*config.Storyblok)
+		logging.SetLevel(nc.Log.ParsedLevel())
+		s.SetTargets(targets(nc))
+		sc.SetSaveInterval(nc.Storyblok.SaveInterval)
+		sc.SetDryRun(nc.Storyblok.DryRun)
+		sc.SetLowConfidenceOnly(nc.Review.LowConfidenceOnly)
+		ss := streams(nc, rq != nil)
+		sc.ReadTranslation(ctx, ss)
+		groups.Set(consumerGroups(ss))
+	})
+
+	prometheus.MustRegister(metrics.NewStreamCollector(rdb, groups))
+
+	hc := health.NewChecker(5 * time.Second)
+	hc.Add("redis", health.Redis(rdb))
+	hc.Add("consumer_groups", health.Groups(rdb, groups))
+	hc.Add("storyblok_credentials", health.Cached(s.CheckCredentials, time.Minute))
+	go startHTTPServer(hc)
+
+	gs := grpc.NewServer(tracing.ServerOptions()...)
+	hs := grpchealth.NewServer()
+	go startGRPCServer(gs, hs, storyblok.NewGRPCServer(sc))
 
 	// wait for shutdown
 	if <-shutdownCh != nil {
-		fmt.Println("\nShutdown signal detected, gracefully shutting down...")
+		logging.Infof("Shutdown signal detected, gracefully shutting down...")
+		hs.Shutdown()
+		gs.GracefulStop()
 		sc.CloseGracefully()
 	}
-	fmt.Println("bye")
+	logging.Infof("bye")
+}
+
+// consumerGroups returns the consumer groups of the streams, checked by the
+// readiness probe and measured by the metrics.
+func consumerGroups(ss []storyblok.StreamData) []health.Group {
+	groups := make([]health.Group, 0, len(ss))
+	for _, sd := range ss {
+		groups = append(groups, health.Group{Stream: sd.Stream, Group: sd.Group})
+	}
+	return groups
 }
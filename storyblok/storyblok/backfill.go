@@ -0,0 +1,319 @@
+package storyblok
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+const (
+	backfillJobsKey   = "backfill:jobs"
+	backfillKeyPrefix = "backfill:job:"
+	backfillPerPage   = 100
+	backfillRate      = 5
+	// max stories enqueued per second by a backfill
+	maxBackfillRate = 100
+)
+
+// Backfill starts, or resumes from its last checkpoint, a job walking all the
+// stories matching the request and enqueueing the ones missing the language.
+// The job runs in background, reply is set to its current progress.
+func (s *StoryBlok) Backfill(req *types.BackfillRequest, reply *types.Backfill) error {
+	if req.Lang == "" {
+		return status.Error(codes.InvalidArgument, "missing backfill language")
+	}
+	if req.ID == "" {
+		req.ID = req.Lang + ":" + req.StartsWith
+	}
+	if req.Rate <= 0 {
+		req.Rate = backfillRate
+	}
+	if req.Rate > maxBackfillRate {
+		return status.Errorf(codes.InvalidArgument, "backfill rate must be at most %d stories per second", maxBackfillRate)
+	}
+
+	bf, err := s.loadBackfill(req.ID)
+	if err == redis.Nil {
+		bf = &types.Backfill{
+			BackfillRequest: *req,
+			StartedAt:       time.Now().UTC(),
+		}
+	} else if err != nil {
+		return err
+	} else {
+		// keep the checkpoint, restart a failed job
+		bf.Rate = req.Rate
+		bf.Error = ""
+	}
+
+	if !bf.Done && s.startBackfill(bf.ID) {
+		bf.Running = true
+		err = s.saveBackfill(bf)
+		if err != nil {
+			s.stopBackfill(bf.ID)
+			return err
+		}
+		// the job works on its own copy, reply is set to this one
+		run := *bf
+		go s.runBackfill(&run)
+	}
+
+	*reply = *bf
+	return nil
+}
+
+// BackfillProgress sets reply to the progress of the backfill job with the given ID.
+func (s *StoryBlok) BackfillProgress(id *string, reply *types.Backfill) error {
+	bf, err := s.loadBackfill(*id)
+	if err == redis.Nil {
+		return status.Errorf(codes.NotFound, "backfill %s not found", *id)
+	}
+	if err != nil {
+		return err
+	}
+
+	*reply = *bf
+	return nil
+}
+
+// ResumeBackfills restarts all the backfill jobs not completed yet.
+func (s *StoryBlok) ResumeBackfills() error {
+	ids, err := s.rdb.SMembers(backfillJobsKey).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		bf, err := s.loadBackfill(id)
+		if err != nil {
+			logging.Errorf("%s", err)
+			continue
+		}
+		if bf.Done || bf.Error != "" {
+			continue
+		}
+		if s.startBackfill(bf.ID) {
+			logging.Infof("Resuming backfill %s from page %d", bf.ID, bf.Page+1)
+			go s.runBackfill(bf)
+		}
+	}
+	return nil
+}
+
+// startBackfill marks the job as running, returning false if it was running already.
+func (s *StoryBlok) startBackfill(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.backfills[id] {
+		return false
+	}
+	s.backfills[id] = true
+	return true
+}
+
+func (s *StoryBlok) stopBackfill(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.backfills, id)
+}
+
+// runBackfill walks the stories page by page, saving a checkpoint after each one.
+func (s *StoryBlok) runBackfill(bf *types.Backfill) {
+	defer s.stopBackfill(bf.ID)
+
+	// limit the enqueueing rate
+	throttle := time.NewTicker(time.Second / time.Duration(bf.Rate))
+	defer throttle.Stop()
+
+	bf.Running = true
+	for !bf.Done {
+		ss, err := s.backfillPage(bf, bf.Page+1)
+		if err != nil {
+			s.failBackfill(bf, err)
+			return
+		}
+
+		for _, story := range ss {
+			bf.Scanned++
+			if hasTranslation(story, bf.Lang) {
+				continue
+			}
+			draft, err := s.hasDraft(story.ID, bf.Lang)
+			if err != nil {
+				s.failBackfill(bf, err)
+				return
+			}
+			if draft {
+				continue
+			}
+
+			<-throttle.C
+			id, err := s.enqueueStory(story, bf.Lang)
+			if err != nil {
+				s.failBackfill(bf, err)
+				return
+			}
+			logging.With(logging.Fields{
+				"backfill":   bf.ID,
+				"stream":     "storyblok",
+				"message_id": id,
+				"story_id":   story.ID,
+			}).Debugf("Backfill %s: sending message ID %s for story ID %d", bf.ID, id, story.ID)
+			bf.Enqueued++
+		}
+
+		bf.Page++
+		bf.Done = len(ss) < backfillPerPage
+		bf.Running = !bf.Done
+		bf.UpdatedAt = time.Now().UTC()
+
+		err = s.saveBackfill(bf)
+		if err != nil {
+			// the page will be walked again once resumed
+			logging.Errorf("%s", err)
+			return
+		}
+		logging.Infof("Backfill %s: page %d done, %d stories scanned, %d enqueued", bf.ID, bf.Page, bf.Scanned, bf.Enqueued)
+	}
+	logging.Infof("Backfill %s completed", bf.ID)
+}
+
+func (s *StoryBlok) failBackfill(bf *types.Backfill, err error) {
+	logging.Errorf("Backfill %s failed: %s", bf.ID, err)
+	bf.Running = false
+	bf.Error = err.Error()
+	bf.UpdatedAt = time.Now().UTC()
+
+	err = s.saveBackfill(bf)
+	if err != nil {
+		logging.Errorf("%s", err)
+	}
+}
+
+// backfillPage gets a page of the stories matching the backfill job from Storyblok api.
+func (s *StoryBlok) backfillPage(bf *types.Backfill, page int) ([]types.Story, error) {
+	req, err := http.NewRequest("GET", "https://api.storyblok.com/v1/cdn/stories", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	for k, v := range bf.Query {
+		q.Add(k, v)
+	}
+	if bf.StartsWith != "" {
+		q.Set("starts_with", bf.StartsWith)
+	}
+	// stable ordering to resume from a page
+	q.Set("sort_by", "created_at:asc")
+	q.Set("per_page", strconv.Itoa(backfillPerPage))
+	q.Set("page", strconv.Itoa(page))
+	q.Set("token", s.token)
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Add("Accept", "application/json")
+
+	client := httpClient
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("storyblok replied %s", res.Status)
+	}
+
+	ss := struct {
+		Stories []types.Story `json:"stories"`
+	}{}
+
+	err = json.NewDecoder(res.Body).Decode(&ss)
+	if err != nil {
+		return nil, err
+	}
+	return ss.Stories, nil
+}
+
+// enqueueStory puts the story on the stream to be translated into the given
+// language only, the other languages are neither translated nor saved.
+func (s *StoryBlok) enqueueStory(story types.Story, lang string) (string, error) {
+	js, err := json.Marshal(story)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.rdb.XAdd(&redis.XAddArgs{
+		Stream: "storyblok",
+		Values: map[string]interface{}{
+			"story":            js,
+			types.TargetsField: lang,
+		},
+	}).Result()
+	if err != nil {
+		return "", err
+	}
+	metrics.StoriesEnqueued.WithLabelValues("backfill").Inc()
+	return id, nil
+}
+
+func (s *StoryBlok) loadBackfill(id string) (*types.Backfill, error) {
+	js, err := s.rdb.Get(backfillKeyPrefix + id).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var bf types.Backfill
+	err = json.Unmarshal([]byte(js), &bf)
+	if err != nil {
+		return nil, err
+	}
+
+	// running state is known by this process only
+	bf.Running = s.isBackfillRunning(id)
+	return &bf, nil
+}
+
+func (s *StoryBlok) isBackfillRunning(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.backfills[id]
+}
+
+// saveBackfill stores the job progress as checkpoint.
+func (s *StoryBlok) saveBackfill(bf *types.Backfill) error {
+	js, err := json.Marshal(bf)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Set(backfillKeyPrefix+bf.ID, js, 0)
+		pipe.SAdd(backfillJobsKey, bf.ID)
+		return nil
+	})
+	return err
+}
+
+func hasTranslation(story types.Story, code string) bool {
+	for _, lang := range story.Content.Translations {
+		if lang == code {
+			return true
+		}
+	}
+	return false
+}
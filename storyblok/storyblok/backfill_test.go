@@ -0,0 +1,25 @@
+package storyblok
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestBackfillInvalid(t *testing.T) {
+	var s StoryBlok
+	for _, req := range []types.BackfillRequest{
+		{},
+		{Lang: "en", Rate: maxBackfillRate + 1},
+		{Lang: "en", Rate: 2e9},
+	} {
+		var reply types.Backfill
+		err := s.Backfill(&req, &reply)
+		if st, _ := status.FromError(err); err == nil || st.Code() != codes.InvalidArgument {
+			t.Errorf("expected invalid argument for %+v, got %v", req, err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package storyblok
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-redis/redis"
+
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+const entriesPerPage = 100
+
+// NewDatasourceEntries looks for datasource entries missing the value of any
+// dimension and puts them on the stream to be translated.
+func (s *StoryBlok) NewDatasourceEntries(req *types.DatasourceRequest, reply *types.DatasourceReply) error {
+	dss, err := s.datasources()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]struct{}, len(req.Datasources))
+	for _, slug := range req.Datasources {
+		wanted[slug] = struct{}{}
+	}
+
+	reply.Entries = []types.DatasourceEntry{}
+	for _, ds := range dss {
+		if _, ok := wanted[ds.Slug]; len(wanted) > 0 && !ok {
+			continue
+		}
+
+		entries, err := s.untranslatedEntries(ds)
+		if err != nil {
+			return err
+		}
+		reply.Entries = append(reply.Entries, entries...)
+	}
+
+	// add messages to the stream in a single transaction
+	_, err = s.rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, entry := range reply.Entries {
+			js, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+
+			pipe.XAdd(&redis.XAddArgs{
+				Stream: "storyblok",
+				Values: map[string]interface{}{"entry": js},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.Infof("Sent %d datasource entries to be translated", len(reply.Entries))
+	return nil
+}
+
+// untranslatedEntries returns the entries of the datasource missing the value
+// of at least one dimension.
+func (s *StoryBlok) untranslatedEntries(ds types.Datasource) ([]types.DatasourceEntry, error) {
+	entries, err := s.datasourceEntries(ds.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make(map[int]bool)
+	for _, dim := range ds.Dimensions {
+		dimEntries, err := s.datasourceEntries(ds.ID, dim.EntryValue)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range dimEntries {
+			if e.DimensionValue == "" {
+				missing[e.ID] = true
+			}
+		}
+	}
+
+	var untranslated []types.DatasourceEntry
+	for _, e := range entries {
+		if missing[e.ID] && e.Value != "" {
+			e.DatasourceID = ds.ID
+			untranslated = append(untranslated, e)
+		}
+	}
+	return untranslated, nil
+}
+
+// datasources gets all the datasources of the space from Storyblok management api.
+func (s *StoryBlok) datasources() ([]types.Datasource, error) {
+	var body struct {
+		Datasources []types.Datasource `json:"datasources"`
+	}
+	err := s.mapiGet(fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s/datasources", s.space), nil, &body)
+	if err != nil {
+		return nil, err
+	}
+	return body.Datasources, nil
+}
+
+// datasource gets a single datasource with its dimensions.
+func (s *StoryBlok) datasource(id int) (types.Datasource, error) {
+	var body struct {
+		Datasource types.Datasource `json:"datasource"`
+	}
+	err := s.mapiGet(fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s/datasources/%d", s.space, id), nil, &body)
+	if err != nil {
+		return types.Datasource{}, err
+	}
+	return body.Datasource, nil
+}
+
+// datasourceEntries gets all the entries of a datasource with their values in
+// the given dimension (the default one when empty).
+func (s *StoryBlok) datasourceEntries(datasourceID int, dimension string) ([]types.DatasourceEntry, error) {
+	var entries []types.DatasourceEntry
+	for page := 1; ; page++ {
+		params := map[string]string{
+			"datasource_id": strconv.Itoa(datasourceID),
+			"page":          strconv.Itoa(page),
+			"per_page":      strconv.Itoa(entriesPerPage),
+		}
+		if dimension != "" {
+			params["dimension"] = dimension
+		}
+
+		var body struct {
+			Entries []types.DatasourceEntry `json:"datasource_entries"`
+		}
+		err := s.mapiGet(fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s/datasource_entries", s.space), params, &body)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, body.Entries...)
+		if len(body.Entries) < entriesPerPage {
+			return entries, nil
+		}
+	}
+}
+
+// saveEntry writes the translated value of the entry in the dimension of the
+// given language, unless the datasource has no such dimension or the value is
+// already there.
+func (s *StoryBlok) saveEntry(entry types.DatasourceEntry, code string) error {
+	ds, err := s.datasource(entry.DatasourceID)
+	if err != nil {
+		return err
+	}
+
+	var dim *types.Dimension
+	for i := range ds.Dimensions {
+		if ds.Dimensions[i].EntryValue == code {
+			dim = &ds.Dimensions[i]
+		}
+	}
+	if dim == nil {
+		logging.Warnf("Datasource %s has no dimension %s, skipping entry ID %d", ds.Slug, code, entry.ID)
+		return nil
+	}
+
+	// ensure that translation has not been persisted yet.
+	current, err := s.datasourceEntries(ds.ID, code)
+	if err != nil {
+		return err
+	}
+	for _, e := range current {
+		if e.ID == entry.ID && e.DimensionValue != "" {
+			return nil
+		}
+	}
+
+	body := map[string]interface{}{
+		"datasource_entry": map[string]interface{}{
+			"name":            entry.Name,
+			"value":           entry.Value,
+			"dimension_value": entry.DimensionValue,
+		},
+		"dimension_id": dim.ID,
+	}
+	jbody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s/datasource_entries/%d", s.space, entry.ID), bytes.NewBuffer(jbody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", s.oauth)
+
+	client := httpClient
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("saving entry ID %d: storyblok replied %s", entry.ID, res.Status)
+	}
+	return nil
+}
+
+// mapiGet sends a GET request to the Storyblok management api and decodes the response into v.
+func (s *StoryBlok) mapiGet(url string, params map[string]string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	q := req.URL.Query()
+	for k, p := range params {
+		q.Add(k, p)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", s.oauth)
+
+	client := httpClient
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("storyblok replied %s", res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
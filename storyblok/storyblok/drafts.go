@@ -0,0 +1,58 @@
+package storyblok
+
+import (
+	"strconv"
+
+	"github.com/go-redis/redis"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// Translations saved without publishing the story, low confidence ones, are
+// missing from the published stories read through the CDN. They are recorded
+// in the set "drafts:<story ID>" of their language codes, so that the stories
+// are not translated and billed again while waiting for someone to publish them.
+const draftsKeyPrefix = "drafts:"
+
+func draftsKey(id int) string {
+	return draftsKeyPrefix + strconv.Itoa(id)
+}
+
+// setDraft records whether the translation of the story in the language has
+// been saved as draft, or published.
+func (s *StoryBlok) setDraft(id int, code string, draft bool) error {
+	if draft {
+		return s.rdb.SAdd(draftsKey(id), code).Err()
+	}
+	return s.rdb.SRem(draftsKey(id), code).Err()
+}
+
+// hasDraft tells whether the translation of the story in the language has
+// been saved as draft.
+func (s *StoryBlok) hasDraft(id int, code string) (bool, error) {
+	return s.rdb.SIsMember(draftsKey(id), code).Result()
+}
+
+// withoutDrafts returns the stories missing target languages other than the
+// ones saved as draft.
+func (s *StoryBlok) withoutDrafts(ss []types.Story) ([]types.Story, error) {
+	cmds := make([]*redis.StringSliceCmd, len(ss))
+	_, err := s.rdb.Pipelined(func(pipe redis.Pipeliner) error {
+		for i, story := range ss {
+			cmds[i] = pipe.SMembers(draftsKey(story.ID))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	missing := make([]types.Story, 0, len(ss))
+	for i, story := range ss {
+		translations := mergeTranslations(story.Content.Translations, cmds[i].Val())
+		if !s.Targets().Complete(story.Content.Component, translations) {
+			missing = append(missing, story)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,128 @@
+package storyblok
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/pb"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// GRPCServer serves the StoryBlok methods over gRPC.
+type GRPCServer struct {
+	c *sbConsumer
+}
+
+// NewGRPCServer returns a gRPC server saving translations through the given consumer.
+func NewGRPCServer(c *sbConsumer) *GRPCServer {
+	return &GRPCServer{c: c}
+}
+
+// NewStories fetches the new stories from Storyblok and puts them on the stream to be translated.
+func (g *GRPCServer) NewStories(ctx context.Context, req *pb.NewStoriesRequest) (*pb.NewStoriesReply, error) {
+	var ss []types.Story
+	err := withContext(ctx, func() error {
+		var err error
+		ss, err = g.c.enqueueNewStories(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.NewStoriesReply{Stories: make([]*pb.Story, 0, len(ss))}
+	for _, story := range ss {
+		msg, err := pb.NewStory(story)
+		if err != nil {
+			return nil, err
+		}
+		res.Stories = append(res.Stories, msg)
+	}
+	return res, nil
+}
+
+// GetStory returns the current version of the story from Storyblok.
+func (g *GRPCServer) GetStory(ctx context.Context, req *pb.GetStoryRequest) (*pb.Story, error) {
+	var story types.Story
+	err := withContext(ctx, func() error {
+		var err error
+		story, err = g.c.fetchStory(int(req.GetId()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewStory(story)
+}
+
+// Save saves the translated story, or holds it for review when enabled, tracking
+// the outcome on its job.
+func (g *GRPCServer) Save(ctx context.Context, req *pb.SaveRequest) (*pb.SaveReply, error) {
+	if req.GetLang() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing language code")
+	}
+	story, err := req.GetStory().Decode()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if story.ID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing story")
+	}
+
+	ctx = logging.NewContext(ctx, logging.With(logging.Fields{
+		"story_id": story.ID,
+		"lang":     req.GetLang(),
+		"job":      req.GetJob(),
+	}))
+
+	var state job.State
+	err = withContext(ctx, func() error {
+		var err error
+		state, err = g.c.storeStory(ctx, story, req.GetLang(), req.GetJob(), false, false, nil)
+		if err != nil {
+			state = job.Failed
+		}
+
+		jerr := job.Update(g.c.rdb, req.GetJob(), story.ID, req.GetLang(), state, err)
+		if jerr != nil {
+			logging.FromContext(ctx).Errorf("Error updating job: %s", jerr)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SaveReply{State: string(state)}, nil
+}
+
+// withContext runs f giving up once the context is done. f is not interrupted:
+// a save already started completes in background.
+func withContext(ctx context.Context, f func() error) error {
+	if ctx.Err() != nil {
+		return contextError(ctx)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- f()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return contextError(ctx)
+	case err := <-errc:
+		return err
+	}
+}
+
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+	}
+	return status.Error(codes.Canceled, ctx.Err().Error())
+}
@@ -0,0 +1,37 @@
+package storyblok
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// CheckCredentials checks that Storyblok accepts both the CDN token and the
+// management API token.
+func (s *StoryBlok) CheckCredentials(ctx context.Context) error {
+	req, err := http.NewRequest("GET", "https://api.storyblok.com/v1/cdn/spaces/me", nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	q := req.URL.Query()
+	q.Add("token", s.token)
+	req.URL.RawQuery = q.Encode()
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("checking CDN token: storyblok replied %s", res.Status)
+	}
+
+	var space struct{}
+	err = s.mapiGet(fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s", s.space), nil, &space)
+	if err != nil {
+		return fmt.Errorf("checking management token: %s", err)
+	}
+	return nil
+}
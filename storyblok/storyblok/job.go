@@ -0,0 +1,124 @@
+package storyblok
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-redis/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/tracing"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+// NewJob creates a job tracking the translation of the requested stories
+// and puts them on the stream to be translated.
+func (s *StoryBlok) NewJob(req *types.JobRequest, reply *job.Job) error {
+	ctx, span := tracing.Start(tracing.ExtractMap(context.Background(), req.Trace), "StoryBlok.NewJob")
+	defer span.End()
+
+	j, err := s.enqueueJob(ctx, req.StoryIDs, req.Targets, nil)
+	if err != nil {
+		return err
+	}
+	metrics.StoriesEnqueued.WithLabelValues("job").Add(float64(len(req.StoryIDs)))
+	*reply = *j
+	return nil
+}
+
+// Retranslate creates a job translating the requested stories again. When
+// forced, the translations saved already in the requested languages are
+// overridden, bypassing the check done before saving.
+func (s *StoryBlok) Retranslate(req *types.RetranslateRequest, reply *job.Job) error {
+	ctx, span := tracing.Start(tracing.ExtractMap(context.Background(), req.Trace), "StoryBlok.Retranslate")
+	defer span.End()
+
+	var force []string
+	if req.Force {
+		force = req.Langs
+	}
+	j, err := s.enqueueJob(ctx, req.StoryIDs, req.Langs, force)
+	if err != nil {
+		return err
+	}
+	metrics.StoriesEnqueued.WithLabelValues("retranslate").Add(float64(len(req.StoryIDs)))
+	*reply = *j
+	return nil
+}
+
+// enqueueJob creates the job and puts the stories on the stream to be
+// translated into the target languages only, marking the languages to be
+// saved even if present on Storyblok.
+func (s *StoryBlok) enqueueJob(ctx context.Context, storyIDs []int, targets []string, force []string) (*job.Job, error) {
+	if len(storyIDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one story ID is required")
+	}
+	if len(targets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one target language is required")
+	}
+
+	known := make(map[string]struct{})
+	for _, codes := range s.Targets() {
+		for _, code := range codes {
+			known[code] = struct{}{}
+		}
+	}
+	for _, code := range targets {
+		if _, ok := known[code]; !ok {
+			return nil, status.Errorf(codes.FailedPrecondition, "language %s is not a target language", code)
+		}
+	}
+
+	// fetch all the stories before creating the job
+	stories := make([]types.Story, 0, len(storyIDs))
+	for _, id := range storyIDs {
+		story, err := s.fetchStory(id)
+		if err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+
+	j, err := job.Create(s.rdb, storyIDs, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	// add messages to the stream in a single transaction
+	_, err = s.rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, story := range stories {
+			js, err := json.Marshal(story)
+			if err != nil {
+				return err
+			}
+
+			values := map[string]interface{}{
+				"story":   js,
+				job.Field: j.ID,
+				// languages not requested are translated through, not saved
+				types.TargetsField: strings.Join(targets, ","),
+			}
+			if len(force) > 0 {
+				values[types.ForceField] = strings.Join(force, ",")
+			}
+			tracing.Inject(ctx, values)
+
+			pipe.XAdd(&redis.XAddArgs{
+				Stream: "storyblok",
+				Values: values,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.With(logging.Fields{"job": j.ID}).Infof("Job %s created for %d stories", j.ID, len(stories))
+	return j, nil
+}
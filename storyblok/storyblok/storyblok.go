@@ -4,56 +4,123 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-redis/redis"
 
+	"github.com/kind84/polygo/pkg/consumer"
+	"github.com/kind84/polygo/pkg/dryrun"
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/quality"
+	"github.com/kind84/polygo/pkg/review"
+	"github.com/kind84/polygo/pkg/tracing"
 	"github.com/kind84/polygo/pkg/types"
 )
 
+// httpClient sends the requests to Storyblok, counting them by status code.
+var httpClient = &http.Client{
+	Transport: metrics.InstrumentStoryblok(http.DefaultTransport),
+}
+
 type StreamData struct {
 	Stream   string
 	Group    string
 	Consumer string
 	Code     string
+	// the translations on the stream are reviewed already, or carry their own
+	// language code when Code is empty
+	Reviewed bool
 }
 
 type StoryBlok struct {
-	token string
-	oauth string
-	space string
-	rdb   *redis.Client
+	token   string
+	oauth   string
+	space   string
+	targets Targets
+	rdb     *redis.Client
+
+	mu        sync.Mutex
+	backfills map[string]bool // running backfill jobs
+}
+
+// Targets returns the target languages by content type.
+func (s *StoryBlok) Targets() Targets {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.targets
 }
 
+// SetTargets replaces the target languages by content type.
+func (s *StoryBlok) SetTargets(targets Targets) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets = targets
+}
+
+// translation to be saved, either a story or a datasource entry in the given language.
+// Stories carry their source version and job, to be recorded by dry runs.
 type translation struct {
+	ctx    context.Context
 	story  types.Story
+	source types.Story
+	job    string
+	entry  *types.DatasourceEntry
+	code   string
+	dryRun bool
+	// saved without publishing it
+	draft  bool
 	okChan chan bool
 }
 
 type sbConsumer struct {
 	*StoryBlok
+	review        *review.Queue
 	translationCh chan translation
 	shutdownCh    chan struct{}
+	consumers     *consumer.Set
+	// pause between two writes on Storyblok, in nanoseconds
+	saveInterval int64
+	// 1 when recording diffs instead of writing on Storyblok
+	dryRun int32
+	// 1 when only the low confidence translations are held for review
+	lowConfidenceOnly int32
 }
 
-func NewSBClient(token string, oauth string, space string, r *redis.Client) *StoryBlok {
+// default pause between two writes, respecting the Storyblok api rate limit
+const saveInterval = 350 * time.Millisecond
+
+// pause between two reads of the messages left pending by failed attempts
+const retryInterval = time.Minute
+
+func NewSBClient(token string, oauth string, space string, targets Targets, r *redis.Client) *StoryBlok {
 	return &StoryBlok{
-		token: token,
-		oauth: oauth,
-		space: space,
-		rdb:   r,
+		token:     token,
+		oauth:     oauth,
+		space:     space,
+		targets:   targets,
+		rdb:       r,
+		backfills: make(map[string]bool),
 	}
 }
 
-func NewSBConsumer(s *StoryBlok) *sbConsumer {
+// NewSBConsumer returns a consumer saving translations on Storyblok.
+// When a review queue is given translations are held there until approved.
+func NewSBConsumer(s *StoryBlok, q *review.Queue) *sbConsumer {
 	sbc := &sbConsumer{
 		StoryBlok:     s,
+		review:        q,
 		translationCh: make(chan translation),
 		shutdownCh:    make(chan struct{}),
+		consumers:     consumer.NewSet(),
+		saveInterval:  int64(saveInterval),
 	}
 
 	go sbc.saveStories()
@@ -75,14 +142,61 @@ func (s *sbConsumer) shouldExit() bool {
 	return false
 }
 
+// SetSaveInterval sets the pause between two writes on Storyblok.
+func (s *sbConsumer) SetSaveInterval(d time.Duration) {
+	atomic.StoreInt64(&s.saveInterval, int64(d))
+}
+
+// SetDryRun sets whether the translations are recorded as diffs on the
+// dry-run stream instead of being written on Storyblok.
+func (s *sbConsumer) SetDryRun(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&s.dryRun, v)
+}
+
+func (s *sbConsumer) isDryRun() bool {
+	return atomic.LoadInt32(&s.dryRun) == 1
+}
+
+// SetLowConfidenceOnly sets whether only the translations with fields failing
+// the quality check are held for review, the others being saved right away.
+func (s *sbConsumer) SetLowConfidenceOnly(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&s.lowConfidenceOnly, v)
+}
+
 // NewStories asks for new stories to be translated and puts them on a stream.
 func (s *StoryBlok) NewStories(req *types.Request, reply *types.Reply) error {
-	// get new stories from Storyblok api
-	ss, err := s.newStories()
+	ss, err := s.enqueueNewStories(tracing.ExtractMap(context.Background(), req.Trace))
 	if err != nil {
 		return err
 	}
 	reply.Stories = ss
+	return nil
+}
+
+// enqueueNewStories puts the new stories on the stream, along with the trace context.
+func (s *StoryBlok) enqueueNewStories(ctx context.Context) ([]types.Story, error) {
+	ctx, span := tracing.Start(ctx, "StoryBlok.NewStories")
+	defer span.End()
+
+	// get new stories from Storyblok api
+	ss, err := s.newStories()
+	if err != nil {
+		span.RecordError(ctx, err)
+		return nil, err
+	}
+	ss, err = s.withoutDrafts(ss)
+	if err != nil {
+		span.RecordError(ctx, err)
+		return nil, err
+	}
 
 	// create a pipeline to add messages to the stream in a single transaction
 	// TODO transform in a transaction instead of pipe?
@@ -96,10 +210,11 @@ func (s *StoryBlok) NewStories(req *types.Request, reply *types.Reply) error {
 		go func(w *sync.WaitGroup, st types.Story) {
 			js, err := json.Marshal(st)
 			if err != nil {
-				log.Fatalln(err)
+				logging.Fatalf("%s", err)
 			}
 
 			msg := map[string]interface{}{"story": js}
+			tracing.Inject(ctx, msg)
 
 			args := &redis.XAddArgs{
 				Stream: "storyblok",
@@ -112,136 +227,311 @@ func (s *StoryBlok) NewStories(req *types.Request, reply *types.Reply) error {
 			// add message to the pipeline
 			id, err := pipe.XAdd(args).Result()
 			if err != nil {
-				log.Fatalln(err)
+				logging.Fatalf("%s", err)
 			}
 
-			log.Printf("Sending message ID %s for story ID %d", id, st.ID)
+			logging.With(logging.Fields{
+				"stream":     "storyblok",
+				"message_id": id,
+				"story_id":   st.ID,
+			}).Infof("Sending message ID %s for story ID %d", id, st.ID)
 
 			w.Done()
 		}(&wg, story)
 	}
 
 	wg.Wait()
-	log.Println("Wait group done")
+	logging.Debugf("Wait group done")
 
 	// commit the transaction to the stream
 	_, err = pipe.Exec()
 	if err != nil {
-		log.Fatalln(err)
+		logging.Fatalf("%s", err)
 	}
+	metrics.StoriesEnqueued.WithLabelValues("storyblok").Add(float64(len(ss)))
 
-	return nil
+	return ss, nil
 }
 
-// ReadTranslation waits for translations appearing on the stream and saves them.
+// ReadTranslation runs a consumer saving the translations appearing on each of
+// the streams, stopping the consumers of the streams no longer listed. Stopped
+// consumers finish saving the messages at hand, the messages left
+// unacknowledged stay pending in the consumer group.
 func (s *sbConsumer) ReadTranslation(ctx context.Context, streams []StreamData) {
-	for _, stream := range streams {
-		go func(sd StreamData) {
-			// create consumer group if not done yet
-			s.rdb.XGroupCreateMkStream(sd.Stream, sd.Group, "$")
-
-			fmt.Printf("Consumer group %s created\n", sd.Group)
-
-			lastID := "0-0"
-			checkHistory := true
-
-			// listen for translations coming from the stream
-			for {
-				// TODO: use context once storing translation to storyblok is implemented.
-				_, cancel := context.WithCancel(ctx)
-
-				if !checkHistory {
-					lastID = ">"
-				}
-
-				args := redis.XReadGroupArgs{
-					Group:    sd.Group,
-					Consumer: sd.Consumer,
-					// List of streams and ids.
-					Streams: []string{sd.Stream, lastID},
-					Count:   10,
-					Block:   time.Millisecond * 2000,
-					// NoAck   bool
-				}
-
-				items := s.rdb.XReadGroup(&args)
-				if items == nil {
-					// Timeout, check if it's time to exit
-					if s.shouldExit() {
-						cancel()
-						return
-					}
-					continue
-				}
-
-				if len(items.Val()) == 0 || len(items.Val()[0].Messages) == 0 {
-					checkHistory = false
-					continue
-				}
-
-				tStream := items.Val()[0]
-				log.Printf("Consumer %s received %d messages\n", sd.Consumer, len(tStream.Messages))
-				for _, msg := range tStream.Messages {
-					log.Printf("Consumer %s reading message ID %s\n", sd.Consumer, msg.ID)
-					lastID = msg.ID
-
-					var story types.Story
-					jsn := msg.Values["story"].(string)
-					err := json.Unmarshal([]byte(jsn), &story)
-					if err != nil {
-						log.Println(err)
-						continue
-					}
-
-					// ensure that translation has not been persisted yet.
-					saved, err := s.checkTranslation(&story, sd.Code)
-					if err != nil {
-						log.Println(err)
-						continue
-					}
-
-					if !saved {
-						log.Println("saving translation")
-						err = s.prepareStory(&story, sd.Code)
-						if err != nil {
-							log.Println(err)
-							continue
-						}
-						// prepare translation message
-						okChan := make(chan bool)
-						tMsg := translation{
-							story:  story,
-							okChan: okChan,
-						}
-
-						s.translationCh <- tMsg
-
-						saved := <-okChan
-						if !saved {
-							continue
-						}
-					}
-
-					ackScript := redis.NewScript(`
-				return redis.call("xack", KEYS[1], ARGV[1], ARGV[2])
-			`)
-
-					_, err = ackScript.Run(
-						s.rdb,
-						[]string{sd.Stream},        // KEYS
-						[]string{sd.Group, msg.ID}, // ARGV
-					).Result()
-
-					if err != nil {
-						// if an error occurred running the script skip to the next story
-						log.Println(err)
-						continue
-					}
-				}
+	cs := make([]consumer.Consumer, 0, len(streams))
+	for _, sd := range streams {
+		sd := sd
+		cs = append(cs, consumer.Consumer{
+			Group:  sd.Group,
+			Config: fmt.Sprintf("%+v", sd),
+			Run: func(stop <-chan struct{}) {
+				s.consume(ctx, sd, stop)
+			},
+		})
+	}
+	s.consumers.Apply(cs)
+}
+
+// consume waits for translations appearing on the stream and saves them, until
+// the stop channel is closed.
+func (s *sbConsumer) consume(ctx context.Context, sd StreamData, stop <-chan struct{}) {
+	// create the consumer group if not done yet, the stories published before
+	// are translated by a backfill
+	s.rdb.XGroupCreateMkStream(sd.Stream, sd.Group, "$")
+
+	lg := logging.With(logging.Fields{
+		"stream":   sd.Stream,
+		"group":    sd.Group,
+		"consumer": sd.Consumer,
+		"lang":     sd.Code,
+	})
+	lg.Infof("Consumer group %s created", sd.Group)
+
+	lastID := "0-0"
+	checkHistory := true
+	lastRetry := time.Now()
+
+	// listen for translations coming from the stream
+	for {
+		// TODO: use context once storing translation to storyblok is implemented.
+		_, cancel := context.WithCancel(ctx)
+
+		select {
+		case <-stop:
+			lg.Infof("Consumer %s stopped", sd.Consumer)
+			cancel()
+			return
+		default:
+		}
+
+		if !checkHistory && time.Since(lastRetry) >= retryInterval {
+			// read again the messages left pending to retry them
+			checkHistory, lastID, lastRetry = true, "0-0", time.Now()
+		}
+		if !checkHistory {
+			lastID = ">"
+		}
+
+		args := redis.XReadGroupArgs{
+			Group:    sd.Group,
+			Consumer: sd.Consumer,
+			// List of streams and ids.
+			Streams: []string{sd.Stream, lastID},
+			Count:   10,
+			Block:   time.Millisecond * 2000,
+			// NoAck   bool
+		}
+
+		items := s.rdb.XReadGroup(&args)
+		if items == nil {
+			// Timeout, check if it's time to exit
+			if s.shouldExit() {
+				cancel()
+				return
+			}
+			continue
+		}
+
+		if len(items.Val()) == 0 || len(items.Val()[0].Messages) == 0 {
+			checkHistory = false
+			continue
+		}
+
+		tStream := items.Val()[0]
+		lg.Debugf("Consumer %s received %d messages", sd.Consumer, len(tStream.Messages))
+		metrics.MessagesRead.WithLabelValues(sd.Stream, sd.Group).Add(float64(len(tStream.Messages)))
+		for _, msg := range tStream.Messages {
+			ml := lg.With(logging.Fields{
+				"message_id": msg.ID,
+				"job":        job.FromMessage(msg.Values),
+			})
+			ml.Debugf("Consumer %s reading message ID %s", sd.Consumer, msg.ID)
+			lastID = msg.ID
+
+			ctx := logging.NewContext(ctx, ml)
+			var err error
+			if _, ok := msg.Values["entry"]; ok {
+				err = s.handleEntry(ctx, sd, msg)
+			} else {
+				err = s.handleStory(ctx, sd, msg)
+			}
+			if err != nil {
+				// leave the message pending to save it again
+				continue
 			}
 
-		}(stream)
+			ackScript := redis.NewScript(`
+		return redis.call("xack", KEYS[1], ARGV[1], ARGV[2])
+	`)
+
+			_, err = ackScript.Run(
+				s.rdb,
+				[]string{sd.Stream},        // KEYS
+				[]string{sd.Group, msg.ID}, // ARGV
+			).Result()
+
+			if err != nil {
+				// if an error occurred running the script skip to the next story
+				ml.Errorf("Error acknowledging message: %s", err)
+				continue
+			}
+			metrics.MessagesAcked.WithLabelValues(sd.Stream, sd.Group).Inc()
+		}
+	}
+}
+
+// handleStory saves the translated story carried by the message, or queues it for review.
+func (s *sbConsumer) handleStory(ctx context.Context, sd StreamData, msg redis.XMessage) (err error) {
+	ctx, span := tracing.Start(tracing.Extract(ctx, msg.Values), "StoryBlok.handleStory")
+	defer func() {
+		if err != nil {
+			logging.FromContext(ctx).Errorf("Error saving translation: %s", err)
+		}
+		tracing.End(ctx, span, err)
+	}()
+
+	jsn, ok := msg.Values["story"].(string)
+	if !ok {
+		return fmt.Errorf("error parsing message ID %s into string", msg.ID)
+	}
+
+	var story types.Story
+	err = json.Unmarshal([]byte(jsn), &story)
+	if err != nil {
+		return err
+	}
+
+	// approved and imported translations carry their own language code
+	code := sd.Code
+	if lang, ok := msg.Values["lang"].(string); ok {
+		code = lang
+	}
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).With(logging.Fields{
+		"story_id": story.ID,
+		"lang":     code,
+	}))
+
+	// translations made only to be translated into other languages
+	_, downstream := msg.Values[types.DownstreamField]
+	if downstream || !types.Targeted(msg.Values, code) {
+		logging.FromContext(ctx).Debugf("Translation not requested, not saved")
+		return nil
+	}
+
+	report, err := quality.FromMessage(msg.Values)
+	if err != nil {
+		return err
+	}
+
+	jobID := job.FromMessage(msg.Values)
+	force := types.Forced(msg.Values, code)
+	state, err := s.storeStory(ctx, story, code, jobID, sd.Reviewed, force, report)
+	if err != nil {
+		state = job.Failed
+	}
+
+	jerr := job.Update(s.rdb, jobID, story.ID, code, state, err)
+	if jerr != nil {
+		logging.FromContext(ctx).Errorf("Error updating job: %s", jerr)
+	}
+	return err
+}
+
+// storeStory saves the translated story, or queues it for review unless it has
+// been reviewed already, returning the resulting state of the translation.
+// Forced translations override the one saved already, if any. Translations
+// with low confidence fields according to the quality report are queued for
+// review, or saved without publishing them and tagged when reviews are off.
+func (s *sbConsumer) storeStory(ctx context.Context, story types.Story, code string, jobID string, reviewed bool, force bool, report *quality.Report) (job.State, error) {
+	// ensure that translation has not been persisted yet.
+	current, saved, err := s.checkTranslation(&story, code)
+	if err != nil {
+		return "", err
+	}
+	if saved {
+		if !force {
+			return job.Saved, nil
+		}
+		logging.FromContext(ctx).Infof("Overriding the translation saved already")
+	}
+
+	low := len(report.Low()) > 0 && !reviewed
+	if s.review != nil && !reviewed && (low || atomic.LoadInt32(&s.lowConfidenceOnly) == 0) {
+		// hold the translation until a linguist reviews it
+		item, err := s.review.Push(current, story, code, jobID, force, report)
+		if err != nil {
+			return "", err
+		}
+		logging.FromContext(ctx).Infof("Translation queued for review with ID %s", item.ID)
+		return job.Review, nil
+	}
+	markLowConfidence(&story, code, low)
+	if low {
+		logging.FromContext(ctx).Warnf("Low confidence translation, %d fields below the quality threshold", len(report.Low()))
+	}
+
+	logging.FromContext(ctx).Infof("Saving translation")
+	err = s.prepareStory(&story, current, code)
+	if err != nil {
+		return "", err
+	}
+	if story.Content.Translated {
+		logging.FromContext(ctx).Infof("All translations done.")
+	}
+
+	t := translation{ctx: ctx, story: story, source: current, code: code, job: jobID, dryRun: s.isDryRun(), draft: low}
+	err = s.save(t)
+	if err != nil {
+		return "", err
+	}
+	if t.dryRun {
+		return job.DryRun, nil
+	}
+	err = s.setDraft(story.ID, code, low)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("Error recording the draft translation: %s", err)
+	}
+	return job.Saved, nil
+}
+
+// handleEntry saves the translated datasource entry carried by the message.
+// Datasource entries are not held for review.
+func (s *sbConsumer) handleEntry(ctx context.Context, sd StreamData, msg redis.XMessage) (err error) {
+	ctx, span := tracing.Start(tracing.Extract(ctx, msg.Values), "StoryBlok.handleEntry")
+	defer func() {
+		if err != nil {
+			logging.FromContext(ctx).Errorf("Error saving datasource entry: %s", err)
+		}
+		tracing.End(ctx, span, err)
+	}()
+
+	jsn, ok := msg.Values["entry"].(string)
+	if !ok {
+		return fmt.Errorf("error parsing message ID %s into string", msg.ID)
+	}
+
+	var entry types.DatasourceEntry
+	err = json.Unmarshal([]byte(jsn), &entry)
+	if err != nil {
+		return err
 	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).With(logging.Fields{"entry_id": entry.ID}))
+	logging.FromContext(ctx).Infof("Saving translation of datasource entry ID %d", entry.ID)
+	return s.save(translation{ctx: ctx, entry: &entry, code: sd.Code, dryRun: s.isDryRun()})
+}
+
+// save sends the translation to the saver and waits for the outcome.
+func (s *sbConsumer) save(t translation) error {
+	okChan := make(chan bool)
+	t.okChan = okChan
+
+	s.translationCh <- t
+
+	if !<-okChan {
+		return errors.New("translation not saved")
+	}
+	return nil
 }
 
 func (s *StoryBlok) newStories() ([]types.Story, error) {
@@ -259,7 +549,7 @@ func (s *StoryBlok) newStories() ([]types.Story, error) {
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
 
-	client := &http.Client{}
+	client := httpClient
 
 	res, err := client.Do(req)
 	if err != nil {
@@ -278,106 +568,347 @@ func (s *StoryBlok) newStories() ([]types.Story, error) {
 	return ss.Stories, nil
 }
 
-func (s *StoryBlok) checkTranslation(story *types.Story, code string) (bool, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.storyblok.com/v1/cdn/stories/%d", story.ID), nil)
+// checkTranslation fetches the current version of the story and tells
+// whether its translation in the given language has been saved already,
+// published or as draft.
+func (s *StoryBlok) checkTranslation(story *types.Story, code string) (types.Story, bool, error) {
+	current, err := s.fetchStory(story.ID)
+	if err != nil {
+		return types.Story{}, false, err
+	}
+	if hasTranslation(current, code) {
+		return current, true, nil
+	}
+
+	draft, err := s.hasDraft(story.ID, code)
+	if err != nil {
+		return types.Story{}, false, err
+	}
+	return current, draft, nil
+}
+
+// Story gets the published story with the given ID.
+func (s *StoryBlok) Story(id *int, reply *types.Story) error {
+	story, err := s.fetchStory(*id)
 	if err != nil {
-		return false, err
+		return err
+	}
+	*reply = story
+	return nil
+}
+
+// fetchStory gets the published version of the story from Storyblok.
+func (s *StoryBlok) fetchStory(id int) (types.Story, error) {
+	return s.fetchTranslation(id, "")
+}
+
+// fetchTranslation gets the published version of the story in the given
+// language from Storyblok, in the default language when code is empty.
+func (s *StoryBlok) fetchTranslation(id int, code string) (types.Story, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.storyblok.com/v1/cdn/stories/%d", id), nil)
+	if err != nil {
+		return types.Story{}, err
 	}
 
 	q := req.URL.Query()
 	q.Add("token", s.token)
+	if code != "" {
+		q.Add("language", code)
+	}
 	req.URL.RawQuery = q.Encode()
 
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
 
-	client := &http.Client{}
+	client := httpClient
 
 	res, err := client.Do(req)
 	if err != nil {
-		return false, err
+		return types.Story{}, err
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode != http.StatusOK {
+		return types.Story{}, fmt.Errorf("fetching story ID %d: storyblok replied %s", id, res.Status)
+	}
+
 	ss := struct {
 		Story types.Story `json:"story"`
 	}{}
 
 	err = json.NewDecoder(res.Body).Decode(&ss)
 	if err != nil {
-		return false, err
-	}
-
-	for _, lang := range ss.Story.Content.Translations {
-		if lang == code {
-			return true, nil
-		}
+		return types.Story{}, err
 	}
-	return false, nil
+	return ss.Story, nil
 }
 
-func (s *StoryBlok) prepareStory(story *types.Story, code string) error {
+// prepareStory sets the story fields names for the given language and updates
+// the list of translations with the ones already saved on Storyblok.
+// Translated assets are saved along with the current ones.
+func (s *StoryBlok) prepareStory(story *types.Story, current types.Story, code string) error {
 	lang := "__i18n__" + code
 	story.Content.Lang = lang
-	for i, _ := range story.Content.Steps {
+	if story.Content.Image.HasText() {
+		story.Content.Image.SetSource(current.Content.Image)
+	}
+
+	thumbnails := make(map[string]types.Asset, len(current.Content.Steps))
+	for _, stp := range current.Content.Steps {
+		thumbnails[stp.UID] = stp.Thumbnail
+	}
+	for i, stp := range story.Content.Steps {
 		story.Content.Steps[i].Lang = lang
+		if src, ok := thumbnails[stp.UID]; ok && stp.Thumbnail.HasText() {
+			story.Content.Steps[i].Thumbnail.SetSource(src)
+		}
 	}
 	for i, _ := range story.Content.Ingredients.Ingredients {
 		story.Content.Ingredients.Ingredients[i].Lang = lang
 	}
-	story.Content.Translations = append(story.Content.Translations, code)
+	story.Content.Translations = mergeTranslations(current.Content.Translations, story.Content.Translations, []string{code})
+
+	story.Content.Translated = s.Targets().Complete(story.Content.Component, story.Content.Translations)
+
+	return nil
+}
+
+// ResetTranslated looks for the stories flagged as translated that miss any of
+// the target languages and resets their flag, so that they get translated again.
+// It returns the number of stories reset.
+func (s *StoryBlok) ResetTranslated() (int, error) {
+	// collect the stories first, resetting them shifts the pages of the
+	// stories flagged as translated
+	ids, err := s.incompleteTranslated()
+	if err != nil {
+		return 0, err
+	}
+
+	var reset int
+	for _, id := range ids {
+		err = s.resetTranslated(id)
+		if err != nil {
+			return reset, err
+		}
+		logging.Infof("Translated flag reset for story ID %d", id)
+		reset++
+		time.Sleep(350 * time.Millisecond) // storyblok api limit rate
+	}
+	return reset, nil
+}
+
+// incompleteTranslated returns the IDs of the stories flagged as translated
+// that miss any of the target languages.
+func (s *StoryBlok) incompleteTranslated() ([]int, error) {
+	var ids []int
+	for page := 1; ; page++ {
+		req, err := http.NewRequest("GET", "https://api.storyblok.com/v1/cdn/stories", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		q := req.URL.Query()
+		q.Add("starts_with", "recipes")
+		q.Add("filter_query[translated][in]", "true")
+		q.Add("per_page", "100")
+		q.Add("page", strconv.Itoa(page))
+		q.Add("token", s.token)
+		req.URL.RawQuery = q.Encode()
+
+		req.Header.Add("Accept", "application/json")
+
+		client := httpClient
+
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		ss := struct {
+			Stories []types.Story `json:"stories"`
+		}{}
+
+		err = json.NewDecoder(res.Body).Decode(&ss)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(ss.Stories) == 0 {
+			return ids, nil
+		}
+
+		for _, story := range ss.Stories {
+			if !s.Targets().Complete(story.Content.Component, story.Content.Translations) {
+				ids = append(ids, story.ID)
+			}
+		}
+	}
+}
+
+// resetTranslated sets the translated flag of the story to false, leaving
+// untouched all the other content fields (translations included).
+func (s *StoryBlok) resetTranslated(id int) error {
+	url := fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s/stories/%d", s.space, id)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", s.oauth)
+
+	client := httpClient
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
 
-	// TODO: improve this check: Translations must contain all translated language codes.
-	if len(story.Content.Translations) == 2 {
-		log.Println("All translations done.")
-		story.Content.Translated = true
+	// decode the story as raw json to preserve the fields unknown to types.Story
+	var body struct {
+		Story map[string]interface{} `json:"story"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return err
+	}
+
+	content, ok := body.Story["content"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("story ID %d has no content", id)
+	}
+	content["translated"] = false
+
+	jbody, err := json.Marshal(map[string]interface{}{
+		"story":   body.Story,
+		"publish": 1,
+	})
+	if err != nil {
+		return err
 	}
 
-	jsty, err := json.Marshal(story)
+	req, err = http.NewRequest("PUT", url, bytes.NewBuffer(jbody))
 	if err != nil {
 		return err
 	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", s.oauth)
 
-	var prettyJson bytes.Buffer
-	err = json.Indent(&prettyJson, []byte(jsty), "", "\t")
+	res, err = client.Do(req)
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(prettyJson.Bytes()))
+	defer res.Body.Close()
 
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("resetting story ID %d: storyblok replied %s", id, res.Status)
+	}
 	return nil
 }
 
+// saveStories saves the translations one at a time, respecting the Storyblok api rate limit.
 func (s *sbConsumer) saveStories() {
 	for t := range s.translationCh {
-		body := struct {
-			Story   types.Story `json:"story"`
-			Publish int         `json:"publish"`
-		}{
-			Story:   t.story,
-			Publish: 1,
+		ctx, span := tracing.Start(t.ctx, "StoryBlok.saveStories")
+		var err error
+		switch {
+		case t.dryRun && t.entry != nil:
+			logging.FromContext(ctx).Infof("Dry run, datasource entry ID %d not saved", t.entry.ID)
+		case t.dryRun:
+			err = s.recordDiff(t)
+		case t.entry != nil:
+			err = s.saveEntry(*t.entry, t.code)
+		default:
+			err = s.saveStory(t.story, !t.draft)
 		}
-
-		jbody, err := json.Marshal(body)
-
-		req, err := http.NewRequest("PUT", fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s/stories/%d", s.space, t.story.ID), bytes.NewBuffer(jbody))
 		if err != nil {
-			log.Println(err)
-			t.okChan <- false
+			logging.FromContext(ctx).Errorf("Error writing on Storyblok: %s", err)
 		}
+		tracing.End(ctx, span, err)
+		t.okChan <- err == nil
+		time.Sleep(time.Duration(atomic.LoadInt64(&s.saveInterval))) // storyblok api limit rate
+	}
+}
 
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("Authorization", s.oauth)
+// recordDiff puts the diff between the translation saved on Storyblok, if
+// any, and the new one on the dry-run stream.
+func (s *StoryBlok) recordDiff(t translation) error {
+	var current types.Story
+	if hasTranslation(t.source, t.code) {
+		var err error
+		current, err = s.fetchTranslation(t.source.ID, t.code)
+		if err != nil {
+			return err
+		}
+	}
 
-		client := &http.Client{}
+	d := dryrun.NewDiff(t.source, current, t.story, t.code)
+	d.Job = t.job
+	err := dryrun.Add(s.rdb, d)
+	if err != nil {
+		return err
+	}
+	logging.FromContext(t.ctx).Infof("Dry run, diff recorded with ID %s", d.ID)
+	return nil
+}
 
-		res, err := client.Do(req)
-		if err != nil {
-			log.Println(err)
-			t.okChan <- false
+// markLowConfidence tags the story as having a low confidence translation in
+// the language, or removes the tag.
+func markLowConfidence(story *types.Story, code string, low bool) {
+	tag := quality.Tag(code)
+	tags := make([]string, 0, len(story.TagList)+1)
+	for _, t := range story.TagList {
+		if t != tag {
+			tags = append(tags, t)
 		}
-		fmt.Println(res)
-		t.okChan <- true
-		time.Sleep(350 * time.Millisecond) // storyblok api limit rate
 	}
+	if low {
+		tags = append(tags, tag)
+	}
+	story.TagList = tags
+}
+
+// saveStory writes the story on Storyblok, publishing it if asked to.
+func (s *StoryBlok) saveStory(story types.Story, publish bool) error {
+	body := struct {
+		Story   types.Story `json:"story"`
+		Publish int         `json:"publish"`
+	}{
+		Story: story,
+	}
+	if publish {
+		body.Publish = 1
+	}
+
+	jbody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("https://mapi.storyblok.com/v1/spaces/%s/stories/%d", s.space, story.ID), bytes.NewBuffer(jbody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", s.oauth)
+
+	client := httpClient
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("saving story ID %d: storyblok replied %s", story.ID, res.Status)
+	}
+	if !publish {
+		logging.With(logging.Fields{"story_id": story.ID}).Debugf("Story saved as draft, storyblok replied %s", res.Status)
+		return nil
+	}
+	logging.With(logging.Fields{"story_id": story.ID}).Debugf("Story saved and published, storyblok replied %s", res.Status)
+	return nil
 }
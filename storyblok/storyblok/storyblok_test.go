@@ -0,0 +1,23 @@
+package storyblok
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestMarkLowConfidence(t *testing.T) {
+	story := types.Story{TagList: []string{"pasta", "low-confidence-en"}}
+
+	markLowConfidence(&story, "fr", true)
+	if expected := []string{"pasta", "low-confidence-en", "low-confidence-fr"}; !reflect.DeepEqual(story.TagList, expected) {
+		t.Errorf("expected tags %v, got %v", expected, story.TagList)
+	}
+
+	markLowConfidence(&story, "fr", true)
+	markLowConfidence(&story, "en", false)
+	if expected := []string{"pasta", "low-confidence-fr"}; !reflect.DeepEqual(story.TagList, expected) {
+		t.Errorf("expected tags %v, got %v", expected, story.TagList)
+	}
+}
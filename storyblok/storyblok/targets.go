@@ -0,0 +1,54 @@
+package storyblok
+
+// AnyComponent is the key of the target languages applying to every content type
+// without its own configuration.
+const AnyComponent = "*"
+
+// Targets maps each content type (story component) to the language codes
+// its stories have to be translated into.
+type Targets map[string][]string
+
+// For returns the target languages of the given content type.
+func (t Targets) For(component string) []string {
+	if codes, ok := t[component]; ok {
+		return codes
+	}
+	return t[AnyComponent]
+}
+
+// Complete tells whether the translations cover all the target languages
+// of the given content type.
+func (t Targets) Complete(component string, translations []string) bool {
+	codes := t.For(component)
+	if len(codes) == 0 {
+		return false
+	}
+
+	done := make(map[string]struct{}, len(translations))
+	for _, code := range translations {
+		done[code] = struct{}{}
+	}
+	for _, code := range codes {
+		if _, ok := done[code]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTranslations returns the language codes found in all the given lists,
+// without duplicates and keeping their first appearance order.
+func mergeTranslations(lists ...[]string) []string {
+	seen := make(map[string]struct{})
+	merged := []string{}
+	for _, codes := range lists {
+		for _, code := range codes {
+			if _, ok := seen[code]; ok || code == "" {
+				continue
+			}
+			seen[code] = struct{}{}
+			merged = append(merged, code)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,44 @@
+package storyblok
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTargetsComplete(t *testing.T) {
+	targets := Targets{
+		AnyComponent: {"en", "fr"},
+		"news":       {"en"},
+	}
+
+	tests := []struct {
+		component    string
+		translations []string
+		want         bool
+	}{
+		{"recipe", []string{"en", "fr"}, true},
+		{"recipe", []string{"fr", "en", "de"}, true},
+		{"recipe", []string{"en", "en"}, false},
+		{"recipe", nil, false},
+		{"news", []string{"en"}, true},
+	}
+
+	for _, tt := range tests {
+		got := targets.Complete(tt.component, tt.translations)
+		if got != tt.want {
+			t.Errorf("Complete(%q, %v): got %v, want %v", tt.component, tt.translations, got, tt.want)
+		}
+	}
+
+	if (Targets{}).Complete("recipe", []string{"en"}) {
+		t.Error("Complete with no target languages: got true, want false")
+	}
+}
+
+func TestMergeTranslations(t *testing.T) {
+	got := mergeTranslations([]string{"en", "en"}, []string{"fr", "en"}, []string{"de"})
+	want := []string{"en", "fr", "de"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Error merging translations: got %v, want %v", got, want)
+	}
+}
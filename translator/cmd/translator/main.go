@@ -2,42 +2,65 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"net"
+	"net/http"
 	"net/rpc"
 	"net/rpc/jsonrpc"
 	"os"
 	"os/signal"
 	"runtime"
-	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-redis/redis"
-	"github.com/spf13/viper"
-	"golang.org/x/text/language"
-
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kind84/polygo/pkg/config"
+	"github.com/kind84/polygo/pkg/health"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/pb"
+	"github.com/kind84/polygo/pkg/tracing"
 	"github.com/kind84/polygo/translator/translator"
 )
 
-// setting stream data for each stream to be listening on
-var streams = []translator.StreamData{
-	translator.StreamData{
-		StreamFrom: "storyblok",
-		Group:      "translate_it-en",
-		Consumer:   "translator_it-en",
-		StreamTo:   "translation_en",
-		LangFrom:   language.Italian,
-		LangTo:     language.English,
-	},
-	translator.StreamData{
-		StreamFrom: "translation_en",
-		Group:      "translate_en-fr",
-		Consumer:   "translator_en-fr",
-		StreamTo:   "translation_fr",
-		LangFrom:   language.English,
-		LangTo:     language.French,
-	},
+// language pairs translated when none is configured: stories are translated
+// from italian into english, then from english into french
+var defaultPairs = map[string][]string{
+	"it": {"en"},
+	"en": {"fr"},
+}
+
+// streams returns the stream data of the configured language pairs.
+func streams(conf *config.Translation) ([]translator.StreamData, error) {
+	pairs := conf.Pairs
+	if len(pairs) == 0 {
+		pairs = defaultPairs
+	}
+	return translator.Streams(conf.Source, pairs)
+}
+
+// applySettings sets the glossaries, the limits and the backend of the translations.
+func applySettings(conf *config.Translation) {
+	gs := make(map[string]translator.Glossary, len(conf.Glossaries))
+	for pair, g := range conf.Glossaries {
+		gs[pair] = g
+	}
+	translator.SetGlossaries(gs)
+	translator.SetConcurrency(conf.MaxConcurrentRequests)
+	translator.SetQualityCheck(conf.Quality.Enabled, conf.Quality.Threshold)
+
+	err := translator.SetBackend(conf.Backend, translator.Budget{
+		Daily:    conf.Budget.Daily,
+		Monthly:  conf.Budget.Monthly,
+		Fallback: conf.Budget.Fallback,
+	})
+	if err != nil {
+		logging.Errorf("%s", err)
+	}
 }
 
 // start rpc server
@@ -48,28 +71,55 @@ func startServer(rdb *redis.Client) {
 
 	l, err := net.Listen("tcp", ":8090")
 	if err != nil {
-		log.Fatalln("listen error:", err)
+		logging.Fatalf("listen error: %s", err)
 	}
 
 	for {
 		conn, err := l.Accept()
 		if err != nil {
-			log.Fatalln(err)
+			logging.Fatalf("%s", err)
 		}
 
 		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
 	}
 }
 
+// start grpc server, serving the health checks as well
+func startGRPCServer(gs *grpc.Server, hs *grpchealth.Server) {
+	pb.RegisterTranslatorServer(gs, new(translator.GRPCTranslator))
+	healthpb.RegisterHealthServer(gs, hs)
+	hs.SetServingStatus("polygo.Translator", healthpb.HealthCheckResponse_SERVING)
+
+	l, err := net.Listen("tcp", ":8091")
+	if err != nil {
+		logging.Fatalf("listen error: %s", err)
+	}
+
+	err = gs.Serve(l)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+}
+
+// start http server with the health probes and the metrics
+func startHTTPServer(hc *health.Checker) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", hc.Healthz)
+	mux.HandleFunc("/readyz", hc.Readyz)
+	mux.Handle("/metrics", metrics.Handler())
+
+	err := http.ListenAndServe(":8092", mux)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+}
+
 func init() {
-	fmt.Println("Setting up configuration...")
-	viper.SetConfigName("config")
-	viper.AddConfigPath(".")
-	viper.SetEnvPrefix("polygo")
-	viper.AutomaticEnv()
-	replacer := strings.NewReplacer(".", "_")
-	viper.SetEnvKeyReplacer(replacer)
-	viper.ReadInConfig()
+	logging.Infof("Setting up configuration...")
+	err := config.Init()
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
 }
 
 func main() {
@@ -77,38 +127,100 @@ func main() {
 
 	// Wire shutdownCh to get events depending on the OS we are running in
 	if runtime.GOOS == "windows" {
-		fmt.Println("Listening to Windows OS interrupt signal for graceful shutdown.")
+		logging.Infof("Listening to Windows OS interrupt signal for graceful shutdown.")
 		signal.Notify(shutdownCh, os.Interrupt)
 
 	} else {
-		fmt.Println("Listening to SIGINT or SIGTERM for graceful shutdown.")
+		logging.Infof("Listening to SIGINT or SIGTERM for graceful shutdown.")
 		signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
 	}
 
 	ctx := context.Background()
 
+	var conf config.Translator
+	err := config.Load(&conf)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+	logging.Init("polygo-translator", conf.Log.ParsedLevel())
+	logging.Infof("Configuration:\n%s", config.Dump(&conf))
+
+	// export traces when a collector is configured
+	closeTracing, err := tracing.Init("polygo-translator", conf.Tracing.Endpoint, conf.Tracing.SampleRatio)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+	defer closeTracing()
+
+	ss, err := streams(&conf.Translator)
+	if err != nil {
+		logging.Fatalf("%s", err)
+	}
+
 	// setting up redis client
-	rh := viper.GetString("redis.host")
-	rdb := redis.NewClient(&redis.Options{Addr: rh})
+	rdb := redis.NewClient(&redis.Options{Addr: conf.Redis.Host})
+	translator.CountUsage(rdb)
+	applySettings(&conf.Translator)
 
 	// start jsonrpc server
-	fmt.Println("Jsonrpc sever listening on port 8090")
+	logging.Infof("Jsonrpc sever listening on port 8090")
 	go startServer(rdb)
 
+	// start grpc server
+	logging.Infof("gRPC server listening on port 8091")
+	gs := grpc.NewServer(tracing.ServerOptions()...)
+	hs := grpchealth.NewServer()
+	go startGRPCServer(gs, hs)
+
 	defer rdb.Close()
 
+	// start http server
+	groups := health.NewGroupList(consumerGroups(ss))
+	prometheus.MustRegister(metrics.NewStreamCollector(rdb, groups))
+
+	hc := health.NewChecker(5 * time.Second)
+	hc.Add("redis", health.Redis(rdb))
+	hc.Add("consumer_groups", health.Groups(rdb, groups))
+	hc.Add("translation_backend", health.Cached(translator.CheckBackend, time.Minute))
+	logging.Infof("HTTP server listening on port 8092")
+	go startHTTPServer(hc)
+
 	t := translator.NewTranslator(rdb)
 
 	// start reading streams
-	for _, s := range streams {
-		fmt.Printf("Start reading stream %s\n", s.StreamFrom)
-		go t.ReadStreamAndTranslate(ctx, s)
-	}
+	t.Consume(ctx, ss)
+
+	// apply the changes of the config file without restarting
+	config.Watch(&conf, func(next interface{}) {
+		nc := next.(*config.Translator)
+		logging.SetLevel(nc.Log.ParsedLevel())
+		tc := nc.Translator
+		ss, err := streams(&tc)
+		if err != nil {
+			logging.Errorf("%s", err)
+			return
+		}
+		applySettings(&tc)
+		t.Consume(ctx, ss)
+		groups.Set(consumerGroups(ss))
+	})
 
 	// wait for shutdown
 	if <-shutdownCh != nil {
-		fmt.Println("\nShutdown signal detected, gracefully shutting down...")
+		logging.Infof("Shutdown signal detected, gracefully shutting down...")
+		hs.Shutdown()
+		gs.GracefulStop()
 		t.CloseGracefully()
 	}
-	fmt.Println("bye")
+	logging.Infof("bye")
+}
+
+// consumerGroups returns the consumer groups of the streams, checked by the
+// readiness probe and measured by the metrics.
+func consumerGroups(ss []translator.StreamData) []health.Group {
+	groups := make([]health.Group, 0, len(ss))
+	for _, sd := range ss {
+		groups = append(groups, health.Group{Stream: sd.StreamFrom, Group: sd.Group})
+	}
+	return groups
 }
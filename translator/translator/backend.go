@@ -0,0 +1,217 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/translate"
+	"github.com/go-redis/redis"
+	"golang.org/x/text/language"
+
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/usage"
+)
+
+// backend translates a text with a translation service.
+type backend interface {
+	translate(ctx context.Context, text string, source, target language.Tag) (string, error)
+}
+
+// backends by name, as in the settings and in the metrics
+var backends = map[string]backend{
+	"google": cloudBackend{},
+	"gtx":    gtxBackend{client: &http.Client{Timeout: 10 * time.Second}},
+}
+
+// cloudBackend translates with Cloud Translation, billed by character.
+type cloudBackend struct{}
+
+func (cloudBackend) translate(ctx context.Context, text string, source, target language.Tag) (string, error) {
+	client, err := translate.NewClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	resp, err := client.Translate(ctx, []string{text}, target, &translate.Options{Source: source})
+	if err != nil {
+		return "", err
+	}
+	return resp[0].Text, nil
+}
+
+// gtxBackend translates with the endpoint of the Google web widgets, free of
+// charge but rate limited and with no service level.
+type gtxBackend struct {
+	client *http.Client
+}
+
+const gtxURL = "https://translate.googleapis.com/translate_a/single"
+
+func (b gtxBackend) translate(ctx context.Context, text string, source, target language.Tag) (string, error) {
+	sl := "auto"
+	if source != language.Und {
+		sl = source.String()
+	}
+	q := url.Values{
+		"client": {"gtx"},
+		"sl":     {sl},
+		"tl":     {target.String()},
+		"dt":     {"t"},
+		"q":      {text},
+	}
+	req, err := http.NewRequest(http.MethodGet, gtxURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := b.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gtx: unexpected status %s", res.Status)
+	}
+
+	var resp []interface{}
+	err = json.NewDecoder(res.Body).Decode(&resp)
+	if err != nil {
+		return "", fmt.Errorf("gtx: %s", err)
+	}
+	return gtxText(resp)
+}
+
+// gtxText joins the translated sentences of the response, the first element
+// of each entry of its first element.
+func gtxText(resp []interface{}) (string, error) {
+	if len(resp) == 0 {
+		return "", errors.New("gtx: empty response")
+	}
+	sentences, ok := resp[0].([]interface{})
+	if !ok {
+		return "", errors.New("gtx: unexpected response")
+	}
+	var b strings.Builder
+	for _, s := range sentences {
+		parts, ok := s.([]interface{})
+		if !ok || len(parts) == 0 {
+			return "", errors.New("gtx: unexpected response")
+		}
+		text, _ := parts[0].(string)
+		b.WriteString(text)
+	}
+	return b.String(), nil
+}
+
+// Budget limits the characters sent to the translation backend each day and
+// month by target language. Once a budget is used up the translations into the
+// language go to the fallback backend, or wait for the budget to renew when
+// there is none.
+type Budget struct {
+	Daily    map[string]int
+	Monthly  map[string]int
+	Fallback string
+}
+
+// backendSettings are the backend and the budget in use.
+type backendSettings struct {
+	name   string
+	budget Budget
+}
+
+var (
+	// backend settings, replaced as a whole when the settings change
+	currentBackend atomic.Value
+	// store of the usage counts, not counted when nil
+	usageDB *redis.Client
+)
+
+// SetBackend sets the translation backend and its budget.
+func SetBackend(name string, b Budget) error {
+	if _, ok := backends[name]; !ok {
+		return fmt.Errorf("unknown translation backend %q", name)
+	}
+	if _, ok := backends[b.Fallback]; b.Fallback != "" && !ok {
+		return fmt.Errorf("unknown translation backend %q", b.Fallback)
+	}
+	currentBackend.Store(backendSettings{name: name, budget: b})
+	return nil
+}
+
+// CountUsage counts the characters sent to the backends on Redis, enforcing
+// the budgets.
+func CountUsage(rdb *redis.Client) {
+	usageDB = rdb
+}
+
+// errBudget reports the budget of a target language used up with no fallback.
+type errBudget string
+
+func (e errBudget) Error() string {
+	return fmt.Sprintf("translation budget of %s used up", string(e))
+}
+
+// backendFor returns the name of the backend translating into the target
+// language, according to the characters sent already. An errBudget is
+// returned when the budget is used up and there is no fallback.
+func backendFor(target language.Tag) (string, error) {
+	s, ok := currentBackend.Load().(backendSettings)
+	if !ok {
+		s.name = "google"
+	}
+	code := target.String()
+	daily, hasDaily := s.budget.Daily[code]
+	monthly, hasMonthly := s.budget.Monthly[code]
+	if usageDB == nil || (!hasDaily && !hasMonthly) {
+		return s.name, nil
+	}
+
+	day, month, err := usage.Used(usageDB, s.name, code, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("checking the translation budget: %s", err)
+	}
+	exceeded := (hasDaily && day >= int64(daily)) || (hasMonthly && month >= int64(monthly))
+	exhausted := 0.0
+	if exceeded {
+		exhausted = 1
+	}
+	metrics.BudgetExhausted.WithLabelValues(s.name, code).Set(exhausted)
+
+	switch {
+	case !exceeded:
+		return s.name, nil
+	case s.budget.Fallback != "":
+		return s.budget.Fallback, nil
+	}
+	return "", errBudget(code)
+}
+
+// countUsage counts the characters of the request sent to the backend.
+func countUsage(tReq tRequest, chars int) {
+	if usageDB == nil {
+		return
+	}
+	err := usage.Add(usageDB, usage.Record{
+		Backend:    tReq.backend,
+		Source:     tReq.sourceLang.String(),
+		Target:     tReq.destLang.String(),
+		StoryID:    tReq.storyID,
+		Characters: chars,
+		Check:      tReq.check,
+	}, time.Now())
+	if err != nil {
+		logging.With(logging.Fields{
+			"story_id": tReq.storyID,
+			"pair":     pairKey(tReq.sourceLang, tReq.destLang),
+		}).Errorf("Error counting the characters sent to %s: %s", tReq.backend, err)
+	}
+}
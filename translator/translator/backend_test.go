@@ -0,0 +1,54 @@
+package translator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestGtxText(t *testing.T) {
+	var resp []interface{}
+	err := json.Unmarshal([]byte(`[[["Cook the pasta. ","Cuocere la pasta. ",null,null,3],["Drain it.","Scolarla.",null,null,3]],null,"it"]`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := gtxText(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Cook the pasta. Drain it." {
+		t.Errorf("expected both sentences, got %q", text)
+	}
+
+	for _, invalid := range [][]interface{}{nil, {"oops"}, {[]interface{}{"oops"}}} {
+		if _, err := gtxText(invalid); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestSetBackend(t *testing.T) {
+	defer SetBackend("google", Budget{})
+
+	if err := SetBackend("deepl", Budget{}); err == nil {
+		t.Error("expected unknown backend error")
+	}
+	if err := SetBackend("google", Budget{Fallback: "deepl"}); err == nil {
+		t.Error("expected unknown fallback error")
+	}
+
+	err := SetBackend("gtx", Budget{Daily: map[string]int{"en": 1000}, Fallback: "google"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// budgets are not enforced without usage counts
+	name, err := backendFor(language.English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "gtx" {
+		t.Errorf("expected backend gtx, got %s", name)
+	}
+}
@@ -0,0 +1,137 @@
+package translator
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kind84/polygo/pkg/pb"
+)
+
+// GRPCTranslator translates stories on demand over gRPC.
+type GRPCTranslator struct{}
+
+// Translate translates the requested story into the target language.
+func (t *GRPCTranslator) Translate(ctx context.Context, req *pb.TranslateRequest) (*pb.TranslateReply, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	reply, err := t.translate(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return reply, nil
+}
+
+// TranslateStream translates the requested stories concurrently, sending each
+// translation back as soon as it is ready. A failed translation is reported in
+// its reply without closing the stream.
+func (t *GRPCTranslator) TranslateStream(stream pb.Translator_TranslateStreamServer) error {
+	ctx, cancel := withDefaultTimeout(stream.Context())
+	defer cancel()
+
+	replies := make(chan *pb.TranslateReply)
+	recvErr := make(chan error, 1)
+
+	go func() {
+		var wg sync.WaitGroup
+		defer func() {
+			wg.Wait()
+			close(replies)
+		}()
+
+		for {
+			req, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			wg.Add(1)
+			go func(req *pb.TranslateRequest) {
+				defer wg.Done()
+				reply, err := t.translate(ctx, req)
+				if err != nil {
+					reply = &pb.TranslateReply{
+						Target: req.GetTarget(),
+						Error:  grpcError(ctx, err).Error(),
+					}
+				}
+				select {
+				case replies <- reply:
+				case <-ctx.Done():
+				}
+			}(req)
+		}
+	}()
+
+	for reply := range replies {
+		err := stream.Send(reply)
+		if err != nil {
+			return err
+		}
+	}
+
+	select {
+	case err := <-recvErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (t *GRPCTranslator) translate(ctx context.Context, req *pb.TranslateRequest) (*pb.TranslateReply, error) {
+	story, err := req.GetStory().Decode()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	translation, err := translateStory(ctx, story, req.GetSource(), req.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := pb.NewStory(translation)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.TranslateReply{
+		Translation: msg,
+		Target:      req.GetTarget(),
+	}, nil
+}
+
+// withDefaultTimeout bounds the request context by rpcTimeout unless the
+// client set a deadline already.
+func withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, rpcTimeout)
+}
+
+// grpcError maps the error of a translation to its gRPC status.
+func grpcError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case ctx.Err() == context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	}
+	if _, ok := err.(langError); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, ok := err.(errBudget); ok {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,184 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/text/language"
+
+	"github.com/kind84/polygo/pkg/consumer"
+	"github.com/kind84/polygo/pkg/logging"
+)
+
+// Glossary maps source terms to their fixed translation.
+type Glossary map[string]string
+
+// glossaries by language pair, replaced as a whole when the settings change
+var glossaries atomic.Value
+
+// SetGlossaries replaces the glossaries, keyed by language pair as "it-en".
+// Fields matching a term of the glossary, regardless of case, are not sent to
+// the translation service.
+func SetGlossaries(gs map[string]Glossary) {
+	lowered := make(map[string]Glossary, len(gs))
+	for pair, g := range gs {
+		lg := make(Glossary, len(g))
+		for term, translation := range g {
+			lg[strings.ToLower(strings.TrimSpace(term))] = translation
+		}
+		lowered[pair] = lg
+	}
+	glossaries.Store(lowered)
+}
+
+// glossaryTerm returns the fixed translation of the text, if any.
+func glossaryTerm(source, target language.Tag, text string) (string, bool) {
+	gs, _ := glossaries.Load().(map[string]Glossary)
+	if source == language.Und || len(gs) == 0 {
+		return "", false
+	}
+	translation, ok := gs[pairKey(source, target)][strings.ToLower(strings.TrimSpace(text))]
+	return translation, ok
+}
+
+func pairKey(source, target language.Tag) string {
+	return source.String() + "-" + target.String()
+}
+
+// limiter bounds the number of requests in flight, its size can change while
+// requests are running.
+type limiter struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	size int // unlimited when zero
+	used int
+}
+
+func newLimiter() *limiter {
+	l := &limiter{}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+func (l *limiter) acquire() {
+	l.mu.Lock()
+	for l.size > 0 && l.used >= l.size {
+		l.cond.Wait()
+	}
+	l.used++
+	l.mu.Unlock()
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	l.used--
+	l.mu.Unlock()
+	l.cond.Signal()
+}
+
+func (l *limiter) resize(size int) {
+	l.mu.Lock()
+	l.size = size
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+// requests to the translation service
+var requests = newLimiter()
+
+// qualitySettings of the check of the translations.
+type qualitySettings struct {
+	enabled   bool
+	threshold float64
+}
+
+// quality check settings, replaced as a whole when the settings change
+var qualityCheck atomic.Value
+
+// SetQualityCheck turns on or off the check of the translations of the
+// streams: each story is translated back into the source language and the
+// similarity of its fields with the source text is sent along with the
+// translation, fields scoring below the threshold being low confidence.
+func SetQualityCheck(enabled bool, threshold float64) {
+	qualityCheck.Store(qualitySettings{enabled: enabled, threshold: threshold})
+}
+
+// SetConcurrency sets the max number of requests in flight to the translation
+// service, unlimited when zero. Requests already running are not interrupted.
+func SetConcurrency(n int) {
+	requests.resize(n)
+}
+
+// Streams returns the stream data of the consumers translating each language
+// pair. Stories in the source language come from the storyblok stream, the
+// other languages from the stream of their translations.
+func Streams(source string, pairs map[string][]string) ([]StreamData, error) {
+	var streams []StreamData
+	for from, targets := range pairs {
+		langFrom, err := language.Parse(from)
+		if err != nil {
+			return nil, langError{from, err}
+		}
+		streamFrom := "translation_" + from
+		if from == source {
+			streamFrom = "storyblok"
+		}
+
+		for _, to := range targets {
+			langTo, err := language.Parse(to)
+			if err != nil {
+				return nil, langError{to, err}
+			}
+			streams = append(streams, StreamData{
+				StreamFrom: streamFrom,
+				Group:      fmt.Sprintf("translate_%s-%s", from, to),
+				Consumer:   fmt.Sprintf("translator_%s-%s", from, to),
+				StreamTo:   "translation_" + to,
+				LangFrom:   langFrom,
+				LangTo:     langTo,
+				Reach:      reach(pairs, to, map[string]bool{}),
+			})
+		}
+	}
+
+	sort.Slice(streams, func(i, j int) bool { return streams[i].Group < streams[j].Group })
+	return streams, nil
+}
+
+// reach returns the language and the ones it is translated into in turn,
+// following the pairs.
+func reach(pairs map[string][]string, lang string, seen map[string]bool) []string {
+	if seen[lang] {
+		return nil
+	}
+	seen[lang] = true
+	langs := []string{lang}
+	for _, to := range pairs[lang] {
+		langs = append(langs, reach(pairs, to, seen)...)
+	}
+	return langs
+}
+
+// Consume runs a consumer for each of the streams, stopping the consumers of
+// the streams no longer listed. Stopped consumers finish translating the
+// messages at hand, the messages left unacknowledged stay pending in the
+// consumer group until a consumer of the group is started again.
+func (t *translator) Consume(ctx context.Context, streams []StreamData) {
+	cs := make([]consumer.Consumer, 0, len(streams))
+	for _, sd := range streams {
+		sd := sd
+		cs = append(cs, consumer.Consumer{
+			Group:  sd.Group,
+			Config: fmt.Sprintf("%+v", sd),
+			Run: func(stop <-chan struct{}) {
+				logging.With(logging.Fields{"stream": sd.StreamFrom, "group": sd.Group}).Infof("Start reading stream %s", sd.StreamFrom)
+				t.consume(ctx, sd, stop)
+			},
+		})
+	}
+	t.consumers.Apply(cs)
+}
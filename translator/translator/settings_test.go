@@ -0,0 +1,111 @@
+package translator
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	"golang.org/x/text/language"
+
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/types"
+)
+
+func TestStreams(t *testing.T) {
+	streams, err := Streams("it", map[string][]string{"it": {"en", "de"}, "en": {"fr"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []StreamData{
+		{"translation_en", "translate_en-fr", "translator_en-fr", "translation_fr", language.English, language.French, []string{"fr"}},
+		{"storyblok", "translate_it-de", "translator_it-de", "translation_de", language.Italian, language.German, []string{"de"}},
+		{"storyblok", "translate_it-en", "translator_it-en", "translation_en", language.Italian, language.English, []string{"en", "fr"}},
+	}
+	if len(streams) != len(expected) {
+		t.Fatalf("expected %d streams, got %+v", len(expected), streams)
+	}
+	for i, sd := range expected {
+		if !reflect.DeepEqual(streams[i], sd) {
+			t.Errorf("expected %+v, got %+v", sd, streams[i])
+		}
+	}
+
+	_, err = Streams("it", map[string][]string{"it": {"??"}})
+	if _, ok := err.(langError); !ok {
+		t.Errorf("expected langError, got %v", err)
+	}
+}
+
+func TestGlossaryTerm(t *testing.T) {
+	SetGlossaries(map[string]Glossary{"it-en": {"Parmigiano Reggiano": "Parmigiano Reggiano"}})
+	defer SetGlossaries(nil)
+
+	tr, ok := glossaryTerm(language.Italian, language.English, " parmigiano reggiano")
+	if !ok || tr != "Parmigiano Reggiano" {
+		t.Errorf("expected glossary term, got %q %v", tr, ok)
+	}
+	if _, ok := glossaryTerm(language.English, language.French, "parmigiano reggiano"); ok {
+		t.Error("expected no glossary term for en-fr")
+	}
+	if _, ok := glossaryTerm(language.Und, language.English, "parmigiano reggiano"); ok {
+		t.Error("expected no glossary term for a detected source language")
+	}
+}
+
+func TestLimiterResize(t *testing.T) {
+	l := newLimiter()
+	l.resize(1)
+
+	var inFlight, max int32
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.acquire()
+			defer l.release()
+
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}()
+	}
+	wg.Wait()
+
+	if max != 1 {
+		t.Errorf("expected at most 1 request in flight, got %d", max)
+	}
+}
+
+func TestForwardFields(t *testing.T) {
+	msg := redis.XMessage{Values: map[string]interface{}{
+		job.Field:             "j1",
+		types.ForceField:      "en",
+		types.TargetsField:    "fr",
+		types.DownstreamField: "en",
+		"story":               "{}",
+	}}
+
+	fv := forwardFields(msg, []string{"fr", "de"})
+	expected := []string{job.Field, "j1", types.TargetsField, "fr", types.ForceField, "fr,de"}
+	if !reflect.DeepEqual(fv, expected) {
+		t.Errorf("expected fields %v, got %v", expected, fv)
+	}
+
+	delete(msg.Values, types.DownstreamField)
+	fv = forwardFields(msg, []string{"fr", "de"})
+	expected = []string{job.Field, "j1", types.ForceField, "en", types.TargetsField, "fr"}
+	if !reflect.DeepEqual(fv, expected) {
+		t.Errorf("expected fields %v, got %v", expected, fv)
+	}
+}
@@ -3,15 +3,25 @@ package translator
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"cloud.google.com/go/translate"
 	"github.com/go-redis/redis"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/trace"
 	"golang.org/x/text/language"
 
+	"github.com/kind84/polygo/pkg/consumer"
+	"github.com/kind84/polygo/pkg/job"
+	"github.com/kind84/polygo/pkg/logging"
+	"github.com/kind84/polygo/pkg/metrics"
+	"github.com/kind84/polygo/pkg/quality"
+	"github.com/kind84/polygo/pkg/tracing"
 	"github.com/kind84/polygo/pkg/types"
 )
 
@@ -23,6 +33,9 @@ type StreamData struct {
 	StreamTo   string
 	LangFrom   language.Tag
 	LangTo     language.Tag
+	// languages reached by the translations, the target language and the
+	// ones translated from it in turn
+	Reach []string
 }
 
 // The translation request object. Represents a single translation unit.
@@ -32,6 +45,11 @@ type tRequest struct {
 	sourceText string
 	sourceLang language.Tag
 	destLang   language.Tag
+	backend    string
+	// zero for datasource entries
+	storyID int
+	// back-translation of a quality check
+	check bool
 }
 
 // The response object from a single translation request.
@@ -39,21 +57,30 @@ type tResponse struct {
 	ID          string
 	field       string
 	translation string
+	err         error
 }
 
 // The translation message build starting from the stream message.
+// It carries either a story or a datasource entry.
 type tMessage struct {
 	id          string
 	story       types.Story
+	entry       *types.DatasourceEntry
 	translation chan tChannel
 	sourceLang  language.Tag
 	destLang    language.Tag
+	backend     string
+	// back-translation of a quality check
+	check bool
 }
 
 // The channel to send over translations.
 type tChannel struct {
-	id    string
-	story types.Story
+	id      string
+	story   types.Story
+	entry   *types.DatasourceEntry
+	quality *quality.Report
+	err     error
 }
 
 type element struct {
@@ -74,18 +101,32 @@ type translationData struct {
 	fields     map[string]string
 	sourceLang language.Tag
 	destLang   language.Tag
+	backend    string
+	storyID    int
+	check      bool
 }
 
-type Reply struct {
-	ID          interface{} `json:"id"`
-	Translation types.Story `json:"translation"`
+// RPCTranslator translates stories on demand over jsonrpc.
+type RPCTranslator struct{}
+
+// langError reports an invalid language code in a translation request.
+type langError struct {
+	code string
+	err  error
 }
 
-type Request struct {
-	Story types.Story
+func (e langError) Error() string {
+	return fmt.Sprintf("invalid language %q: %s", e.code, e.err)
 }
 
-type RPCTranslator struct{}
+// time allowed to translate a story on demand
+const rpcTimeout = 30 * time.Second
+
+// pause between two checks of a budget used up
+const budgetCheckInterval = time.Minute
+
+// pause between two reads of the messages left pending by failed attempts
+const retryInterval = time.Minute
 
 // translator struct implementing Translator interface.
 // It is responsible of translating data coming from the redis stream
@@ -93,6 +134,7 @@ type RPCTranslator struct{}
 type translator struct {
 	shutdownCh chan struct{}
 	rdb        *redis.Client
+	consumers  *consumer.Set
 }
 
 var units map[string]struct{} = map[string]struct{}{
@@ -107,6 +149,7 @@ func NewTranslator(rdb *redis.Client) *translator {
 	return &translator{
 		shutdownCh: make(chan struct{}),
 		rdb:        rdb,
+		consumers:  consumer.NewSet(),
 	}
 }
 
@@ -124,39 +167,123 @@ func (t *translator) shouldExit() bool {
 	return false
 }
 
-func (t *RPCTranslator) Translate(req *Request, reply *Reply) error {
-	ctx := context.Background()
+// Translate translates the requested story into the target language.
+func (t *RPCTranslator) Translate(req *types.TranslateRequest, reply *types.TranslateReply) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	translation, err := translateStory(ctx, req.Story, req.Source, req.Target)
+	if err != nil {
+		return err
+	}
+	reply.ID = req.Story.ID
+	reply.Translation = translation
+
+	return nil
+}
+
+// translateStory translates the story from the source language, detected when
+// empty, into the target language.
+func translateStory(ctx context.Context, story types.Story, source, target string) (types.Story, error) {
+	sourceLang := language.Und
+	if source != "" {
+		var err error
+		sourceLang, err = language.Parse(source)
+		if err != nil {
+			return types.Story{}, langError{source, err}
+		}
+	}
+	destLang, err := language.Parse(target)
+	if err != nil {
+		return types.Story{}, langError{target, err}
+	}
+
+	backend, err := backendFor(destLang)
+	if err != nil {
+		return types.Story{}, err
+	}
 
 	tChan := make(chan tChannel)
 	defer close(tChan)
 
 	m := tMessage{
-		id:          req.Story.UUID,
-		story:       req.Story,
+		id:          story.UUID,
+		story:       story,
 		translation: tChan,
+		sourceLang:  sourceLang,
+		destLang:    destLang,
+		backend:     backend,
 	}
 
 	go translateRecipe(ctx, m)
 
 	tm := <-tChan
-	reply.Translation = tm.story
-
-	return nil
+	return tm.story, tm.err
 }
 
 // ReadStreamAndTranslate reads from the incoming stream and sends back the translation through the recipient stream
 func (t *translator) ReadStreamAndTranslate(ctx context.Context, sd StreamData) {
-	// create consumer group if not done yet
+	t.consume(ctx, sd, nil)
+}
+
+// consume reads and translates the messages of the stream until the stop
+// channel is closed, checked between each batch of messages.
+func (t *translator) consume(ctx context.Context, sd StreamData, stop <-chan struct{}) {
+	// create the consumer group if not done yet, the stories published before
+	// are translated by a backfill
 	t.rdb.XGroupCreateMkStream(sd.StreamFrom, sd.Group, "$").Result()
 
-	log.Printf("Consumer group %s created\n", sd.Group)
+	lg := logging.With(logging.Fields{
+		"stream":   sd.StreamFrom,
+		"group":    sd.Group,
+		"consumer": sd.Consumer,
+		"pair":     pairKey(sd.LangFrom, sd.LangTo),
+	})
+	lg.Infof("Consumer group %s created", sd.Group)
 
 	lastID := "0-0"
 	checkHistory := true
+	lastRetry := time.Now()
+	paused := false
 
 	for {
 		ctx, cancel := context.WithCancel(ctx)
 
+		select {
+		case <-stop:
+			lg.Infof("Consumer %s stopped", sd.Consumer)
+			cancel()
+			return
+		default:
+		}
+
+		// the budget is checked before each batch of messages
+		backend, err := backendFor(sd.LangTo)
+		if err != nil {
+			if !paused {
+				lg.Warnf("Consumer %s paused: %s", sd.Consumer, err)
+				paused = true
+			}
+			cancel()
+			select {
+			case <-stop:
+				lg.Infof("Consumer %s stopped", sd.Consumer)
+				return
+			case <-t.shutdownCh:
+				return
+			case <-time.After(budgetCheckInterval):
+			}
+			continue
+		}
+		if paused {
+			lg.Infof("Consumer %s resumed translating with %s", sd.Consumer, backend)
+			paused = false
+		}
+
+		if !checkHistory && time.Since(lastRetry) >= retryInterval {
+			// read again the messages left pending to retry them
+			checkHistory, lastID, lastRetry = true, "0-0", time.Now()
+		}
 		if !checkHistory {
 			lastID = ">"
 		}
@@ -192,74 +319,231 @@ func (t *translator) ReadStreamAndTranslate(ctx context.Context, sd StreamData)
 		defer close(tChan)
 
 		sbStream := items.Val()[0]
-		log.Printf("Consumer %s received %d messages\n", sd.Consumer, len(sbStream.Messages))
+		lg.Debugf("Consumer %s received %d messages", sd.Consumer, len(sbStream.Messages))
+		metrics.MessagesRead.WithLabelValues(sd.StreamFrom, sd.Group).Add(float64(len(sbStream.Messages)))
+
+		// number of translations to wait for
+		pending := len(sbStream.Messages)
+		// original messages by ID, to forward their metadata along with the translation
+		received := make(map[string]redis.XMessage, len(sbStream.Messages))
+		// contexts carrying the span of each translation by message ID
+		traces := make(map[string]context.Context, len(sbStream.Messages))
+		// loggers carrying the fields of each message by message ID
+		loggers := make(map[string]*logging.Logger, len(sbStream.Messages))
 		for _, msg := range sbStream.Messages {
 			lastID = msg.ID
 
-			log.Printf("Consumer %s reading message ID %s\n", sd.Consumer, msg.ID)
-			var story types.Story
+			ml := lg.With(logging.Fields{
+				"message_id": msg.ID,
+				"job":        job.FromMessage(msg.Values),
+			})
+			ml.Debugf("Consumer %s reading message ID %s", sd.Consumer, msg.ID)
+
+			// messages targeting other languages only are not translated
+			if !types.Targeted(msg.Values, sd.Reach...) {
+				ml.Debugf("Message ID %s targets none of %v, skipped", msg.ID, sd.Reach)
+				pending--
+				err := t.rdb.XAck(sd.StreamFrom, sd.Group, msg.ID).Err()
+				if err != nil {
+					ml.Errorf("Error acknowledging message: %s", err)
+					continue
+				}
+				metrics.MessagesAcked.WithLabelValues(sd.StreamFrom, sd.Group).Inc()
+				continue
+			}
+
+			m := tMessage{
+				id:          msg.ID,
+				translation: tChan,
+				sourceLang:  sd.LangFrom,
+				destLang:    sd.LangTo,
+				backend:     backend,
+			}
+
+			// datasource entries travel on the same streams as stories
+			if entryStr, ok := msg.Values["entry"].(string); ok {
+				var entry types.DatasourceEntry
+				err := json.Unmarshal([]byte(entryStr), &entry)
+				if err != nil {
+					// if a message is malformed continue to process other messages
+					ml.Errorf("Error decoding datasource entry: %s", err)
+					pending--
+					continue
+				}
+				m.entry = &entry
+				loggers[msg.ID] = ml.With(logging.Fields{"entry_id": entry.ID})
+				received[msg.ID] = msg
+				traces[msg.ID] = startTranslation(ctx, sd, msg)
+
+				go translateEntry(traces[msg.ID], m)
+				continue
+			}
 
 			storyStr, ok := msg.Values["story"].(string)
 			if !ok {
-				log.Printf("Error parsing message ID %v into string.", msg.ID)
+				ml.Errorf("Error parsing message ID %v into string.", msg.ID)
+				pending--
 				continue
 			}
 
-			err := json.Unmarshal([]byte(storyStr), &story)
+			err = json.Unmarshal([]byte(storyStr), &m.story)
 			if err != nil {
 				// if a message is malformed continue to process other messages
-				log.Println(err)
+				ml.Errorf("Error decoding story: %s", err)
+				pending--
 				continue
 			}
+			loggers[msg.ID] = ml.With(logging.Fields{"story_id": m.story.ID})
+			received[msg.ID] = msg
+			traces[msg.ID] = startTranslation(ctx, sd, msg)
 
-			m := tMessage{
-				id:          msg.ID,
-				story:       story,
-				translation: tChan,
-				sourceLang:  sd.LangFrom,
-				destLang:    sd.LangTo,
-			}
-
-			go translateRecipe(ctx, m)
+			t.updateJob(msg, m.story.ID, sd.LangTo.String(), job.Translating, nil)
+			go translateChecked(traces[msg.ID], m)
 		}
 
-		for i := 0; i < len(sbStream.Messages); i++ {
+		for i := 0; i < pending; i++ {
 			tMsg := <-tChan
+			ml := loggers[tMsg.id]
+			mctx := traces[tMsg.id]
+			span := trace.SpanFromContext(mctx)
+			if tMsg.err != nil {
+				// leave the message pending to translate it again
+				ml.Errorf("Error translating message ID %s: %s", tMsg.id, tMsg.err)
+				// the languages translated from this one fail as well
+				for _, code := range sd.Reach {
+					t.updateJob(received[tMsg.id], tMsg.story.ID, code, job.Failed, tMsg.err)
+				}
+				tracing.End(mctx, span, tMsg.err)
+				continue
+			}
+
+			key := "story"
+			var payload interface{} = tMsg.story
+			if tMsg.entry != nil {
+				key = "entry"
+				payload = tMsg.entry
+			}
 
-			js, err := json.Marshal(tMsg.story)
+			js, err := json.Marshal(payload)
 			if err != nil {
 				// if a story is malformed continue to process other stories
-				log.Println(err)
+				ml.Errorf("Error encoding translation: %s", err)
+				tracing.End(mctx, span, err)
 				continue
 			}
 
 			ackNaddScript := redis.NewScript(`
 				if redis.call("xack", KEYS[1], ARGV[1], ARGV[2]) == 1 then
-					return redis.call("xadd", KEYS[2], "*", ARGV[3], ARGV[4])
+					return redis.call("xadd", KEYS[2], "*", unpack(ARGV, 3))
 				end
 				return false
 			`)
 
+			argv := []string{sd.Group, tMsg.id, key, string(js)}
+			argv = append(argv, forwardFields(received[tMsg.id], sd.Reach)...)
+			if tMsg.quality != nil {
+				qjs, err := json.Marshal(tMsg.quality)
+				if err != nil {
+					ml.Errorf("Error encoding quality report: %s", err)
+					tracing.End(mctx, span, err)
+					continue
+				}
+				argv = append(argv, quality.Field, string(qjs))
+				if low := tMsg.quality.Low(); len(low) > 0 {
+					ml.Infof("Translation for message ID %s has %d low confidence fields", tMsg.id, len(low))
+				}
+			}
+			argv = append(argv, traceFields(mctx)...)
+
 			_, err = ackNaddScript.Run(
 				t.rdb,
 				[]string{sd.StreamFrom, sd.StreamTo}, // KEYS
-				[]string{sd.Group, tMsg.id, "story", string(js)}, // ARGV
+				argv,                                 // ARGV
 			).Result()
+			tracing.End(mctx, span, err)
 
 			if err != nil {
 				// if an error occurred running the script skip to the next story
-				log.Println(err)
+				ml.Errorf("Error sending translation: %s", err)
 				continue
 			}
-			log.Printf("Translation for message ID %s sent.\n", tMsg.id)
+			metrics.MessagesAcked.WithLabelValues(sd.StreamFrom, sd.Group).Inc()
+			ml.Infof("Translation for message ID %s sent.", tMsg.id)
+			t.updateJob(received[tMsg.id], tMsg.story.ID, sd.LangTo.String(), job.Translated, nil)
 		}
 	}
 }
 
+// metadata fields of the stream messages forwarded along with the translation
+var metaFields = []string{job.Field, types.ForceField, types.TargetsField}
+
+// forwardFields returns the metadata fields of the message as field/value pairs.
+// The translations of reviewed translations sent downstream are saved in the
+// languages reached even if present on Storyblok.
+func forwardFields(msg redis.XMessage, reach []string) []string {
+	_, downstream := msg.Values[types.DownstreamField]
+
+	var fv []string
+	for _, f := range metaFields {
+		if downstream && f == types.ForceField {
+			continue
+		}
+		if v, ok := msg.Values[f].(string); ok {
+			fv = append(fv, f, v)
+		}
+	}
+	if downstream {
+		fv = append(fv, types.ForceField, strings.Join(reach, ","))
+	}
+	return fv
+}
+
+// startTranslation starts the span of the translation of a message, following
+// the trace of the message if any.
+func startTranslation(ctx context.Context, sd StreamData, msg redis.XMessage) context.Context {
+	ctx, _ = tracing.Start(
+		tracing.Extract(ctx, msg.Values),
+		"translator.ReadStreamAndTranslate",
+		kv.String("stream", sd.StreamFrom),
+		kv.String("message.id", msg.ID),
+		kv.String("lang", sd.LangTo.String()),
+	)
+	return ctx
+}
+
+// traceFields returns the stream message fields carrying the trace context,
+// for the next service to continue the trace.
+func traceFields(ctx context.Context) []string {
+	values := make(map[string]interface{})
+	tracing.Inject(ctx, values)
+
+	var fields []string
+	for k, v := range values {
+		fields = append(fields, k, v.(string))
+	}
+	return fields
+}
+
+// updateJob sets the state of the translation of the story, if the message belongs to a job.
+func (t *translator) updateJob(msg redis.XMessage, storyID int, lang string, state job.State, cause error) {
+	err := job.Update(t.rdb, job.FromMessage(msg.Values), storyID, lang, state, cause)
+	if err != nil {
+		logging.With(logging.Fields{
+			"message_id": msg.ID,
+			"story_id":   storyID,
+			"job":        job.FromMessage(msg.Values),
+			"lang":       lang,
+		}).Errorf("Error updating job: %s", err)
+	}
+}
+
 // translateRecipe receives a translation message to translate a single recipe.
 // It is responsible to group fields homogeneously, send them to be translated
 // and collect translations.
 func translateRecipe(ctx context.Context, m tMessage) {
+	ctx, span := tracing.Start(ctx, "translateRecipe", kv.Int("story.id", m.story.ID))
+	defer span.End()
+
 	// m.Translation <- TMessage{
 	// 	ID:    m.ID,
 	// 	Story: m.Story,
@@ -267,7 +551,7 @@ func translateRecipe(ctx context.Context, m tMessage) {
 	// return
 
 	td := translationData{
-		id: string(m.story.ID),
+		id: strconv.Itoa(m.story.ID),
 		fields: map[string]string{
 			"Extra":       m.story.Content.Extra,
 			"Title":       m.story.Content.Title,
@@ -277,6 +561,9 @@ func translateRecipe(ctx context.Context, m tMessage) {
 		},
 		sourceLang: m.sourceLang,
 		destLang:   m.destLang,
+		backend:    m.backend,
+		storyID:    m.story.ID,
+		check:      m.check,
 	}
 
 	// copy recipe object and do reflection on the copy
@@ -285,9 +572,11 @@ func translateRecipe(ctx context.Context, m tMessage) {
 	resChan := make(chan tResponse)
 	stpChan := make(chan tResponse)
 	igrChan := make(chan tResponse)
+	astChan := make(chan tResponse)
 	defer close(resChan)
 	defer close(stpChan)
 	defer close(igrChan)
+	defer close(astChan)
 
 	go translateFields(ctx, td, resChan)
 
@@ -305,6 +594,9 @@ func translateRecipe(ctx context.Context, m tMessage) {
 			},
 			sourceLang: m.sourceLang,
 			destLang:   m.destLang,
+			backend:    m.backend,
+			storyID:    m.story.ID,
+			check:      m.check,
 		}
 		sfm[stp.UID] = map[string]string{
 			"Title":   "",
@@ -324,6 +616,9 @@ func translateRecipe(ctx context.Context, m tMessage) {
 			},
 			sourceLang: m.sourceLang,
 			destLang:   m.destLang,
+			backend:    m.backend,
+			storyID:    m.story.ID,
+			check:      m.check,
 		}
 		ifm[strconv.Itoa(i)] = map[string]string{
 			"Name": "",
@@ -333,20 +628,76 @@ func translateRecipe(ctx context.Context, m tMessage) {
 		go translateFields(ctx, igrFields, igrChan)
 	}
 
+	// launch goroutine for each asset carrying alt text and title,
+	// the recipe image and the steps thumbnails
+	assets := make(map[string]*types.Asset)
+	if s.Content.Image.HasText() {
+		assets["image"] = &s.Content.Image
+	}
+	for i, stp := range s.Content.Steps {
+		if stp.Thumbnail.HasText() {
+			assets["steps."+stp.UID] = &s.Content.Steps[i].Thumbnail
+		}
+	}
+	afm := make(map[string]map[string]string, len(assets))
+	for id, ast := range assets {
+		astFields := translationData{
+			id: id,
+			fields: map[string]string{
+				"Alt":   ast.Alt,
+				"Title": ast.Title,
+			},
+			sourceLang: m.sourceLang,
+			destLang:   m.destLang,
+			backend:    m.backend,
+			storyID:    m.story.ID,
+			check:      m.check,
+		}
+		afm[id] = map[string]string{
+			"Alt":   "",
+			"Title": "",
+		}
+
+		go translateFields(ctx, astFields, astChan)
+	}
+
 	// get the reflection Value for the story Content to search for its fields
 	val := reflect.ValueOf(&s.Content).Elem()
 
+	// first translation error, if any
+	var tErr error
+	setErr := func(err error) bool {
+		if err != nil && tErr == nil {
+			tErr = err
+		}
+		return err != nil
+	}
+
 	// wait for all channels to return the translation
-	totFields := len(td.fields) + (len(s.Content.Steps) * 2) + (len(s.Content.Ingredients.Ingredients) * 2)
+	totFields := len(td.fields) + (len(s.Content.Steps) * 2) + (len(s.Content.Ingredients.Ingredients) * 2) + (len(assets) * 2)
 	for i := 0; i < totFields; i++ { // TODO: use steps fields count instead of the hardcoded number
 		select {
 		case t := <-resChan:
+			if setErr(t.err) {
+				continue
+			}
 			// search the field name with reflection
 			val.FieldByName(t.field).SetString(t.translation)
 		case stpT := <-stpChan:
+			if setErr(stpT.err) {
+				continue
+			}
 			sfm[stpT.ID][stpT.field] = stpT.translation
 		case igrT := <-igrChan:
+			if setErr(igrT.err) {
+				continue
+			}
 			ifm[igrT.ID][igrT.field] = igrT.translation
+		case astT := <-astChan:
+			if setErr(astT.err) {
+				continue
+			}
+			afm[astT.ID][astT.field] = astT.translation
 		}
 	}
 
@@ -364,8 +715,27 @@ func translateRecipe(ctx context.Context, m tMessage) {
 		s.Content.Ingredients.Ingredients[i].Unit = im["Unit"]
 	}
 
+	// retrieve assets translations from map
+	for id, ast := range assets {
+		ast.Alt = afm[id]["Alt"]
+		ast.Title = afm[id]["Title"]
+	}
+
+	if tErr != nil {
+		span.RecordError(ctx, tErr)
+		m.translation <- tChannel{
+			id:    m.id,
+			story: s,
+			err:   tErr,
+		}
+		return
+	}
+
 	// send translated recipe over the channel
-	log.Printf("Translated message ID %s\n", m.id)
+	logging.With(logging.Fields{
+		"story_id": m.story.ID,
+		"pair":     pairKey(m.sourceLang, m.destLang),
+	}).Debugf("Translated message ID %s", m.id)
 	tm := tChannel{
 		id:    m.id,
 		story: s,
@@ -373,11 +743,112 @@ func translateRecipe(ctx context.Context, m tMessage) {
 	m.translation <- tm
 }
 
+// translateChecked translates the recipe and, when the quality check is on,
+// translates it back into the source language scoring each field against the
+// source text. The translation fails if the back-translation does.
+func translateChecked(ctx context.Context, m tMessage) {
+	qc, _ := qualityCheck.Load().(qualitySettings)
+	if !qc.enabled {
+		translateRecipe(ctx, m)
+		return
+	}
+
+	out := m.translation
+	tChan := make(chan tChannel)
+	defer close(tChan)
+	m.translation = tChan
+
+	go translateRecipe(ctx, m)
+	tm := <-tChan
+	if tm.err != nil {
+		out <- tm
+		return
+	}
+
+	back := m
+	back.story = tm.story
+	back.sourceLang, back.destLang = m.destLang, m.sourceLang
+	back.check = true
+	go translateRecipe(ctx, back)
+	bm := <-tChan
+	if bm.err != nil {
+		tm.err = fmt.Errorf("back-translating: %s", bm.err)
+		out <- tm
+		return
+	}
+
+	tm.quality = quality.Compare(m.story, bm.story, qc.threshold)
+	labels := []string{m.sourceLang.String(), m.destLang.String()}
+	for _, f := range tm.quality.Fields {
+		metrics.TranslationQuality.WithLabelValues(labels...).Observe(f.Score)
+	}
+	out <- tm
+}
+
+// translateEntry receives a translation message to translate a single datasource entry.
+// The text of the previous dimension is translated if any, the default value otherwise.
+func translateEntry(ctx context.Context, m tMessage) {
+	entry := *m.entry
+
+	ctx, span := tracing.Start(ctx, "translateEntry", kv.Int("entry.id", entry.ID))
+	defer span.End()
+
+	source := entry.DimensionValue
+	if source == "" {
+		source = entry.Value
+	}
+
+	td := translationData{
+		id: strconv.Itoa(entry.ID),
+		fields: map[string]string{
+			"Value": source,
+		},
+		sourceLang: m.sourceLang,
+		destLang:   m.destLang,
+		backend:    m.backend,
+	}
+
+	resChan := make(chan tResponse)
+	defer close(resChan)
+
+	go translateFields(ctx, td, resChan)
+
+	t := <-resChan
+	if t.err != nil {
+		span.RecordError(ctx, t.err)
+		m.translation <- tChannel{
+			id:  m.id,
+			err: t.err,
+		}
+		return
+	}
+	entry.DimensionValue = t.translation
+
+	// send translated entry over the channel
+	logging.With(logging.Fields{
+		"entry_id": entry.ID,
+		"pair":     pairKey(m.sourceLang, m.destLang),
+	}).Debugf("Translated message ID %s", m.id)
+	m.translation <- tChannel{
+		id:    m.id,
+		entry: &entry,
+	}
+}
+
 // translateFields receives a block of fields to be translated, filters those that need
 // translation and sends each of them to be translated. Once translated it sends translation
 // back through the response channel.
 func translateFields(ctx context.Context, td translationData, resChan chan (tResponse)) {
 	for k, v := range td.fields {
+		if translation, ok := glossaryTerm(td.sourceLang, td.destLang, v); ok {
+			resChan <- tResponse{
+				ID:          td.id,
+				field:       k,
+				translation: translation,
+			}
+			continue
+		}
+
 		// numbers, units and empty fields don't need translation
 		_, unit := units[v]
 		if _, err := strconv.ParseFloat(v, 64); err != nil && v != "" && !unit {
@@ -387,6 +858,9 @@ func translateFields(ctx context.Context, td translationData, resChan chan (tRes
 				sourceText: v,
 				sourceLang: td.sourceLang,
 				destLang:   td.destLang,
+				backend:    td.backend,
+				storyID:    td.storyID,
+				check:      td.check,
 			}
 
 			go func() { resChan <- translateText(ctx, tReq) }()
@@ -401,62 +875,62 @@ func translateFields(ctx context.Context, td translationData, resChan chan (tRes
 	}
 }
 
-// translateText is responsible to call the translation service (Google Cloud)
-// asking for the translation of a single field and send back a translation response object.
-func translateText(ctx context.Context, tReq tRequest) tResponse {
+// CheckBackend checks that Cloud Translation is reachable with the configured
+// credentials, when it is the translation backend.
+func CheckBackend(ctx context.Context) error {
+	if s, ok := currentBackend.Load().(backendSettings); ok && s.name != "google" {
+		return nil
+	}
+
 	client, err := translate.NewClient(ctx)
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 	defer client.Close()
 
-	opts := &translate.Options{
-		Source: tReq.sourceLang,
+	_, err = client.SupportedLanguages(ctx, language.English)
+	return err
+}
+
+// translateText is responsible to call the translation backend asking for the
+// translation of a single field and send back a translation response object.
+func translateText(ctx context.Context, tReq tRequest) tResponse {
+	requests.acquire()
+	defer requests.release()
+
+	be, ok := backends[tReq.backend]
+	if !ok {
+		return tResponse{ID: tReq.ID, field: tReq.field, err: fmt.Errorf("unknown translation backend %q", tReq.backend)}
 	}
 
-	resp, err := client.Translate(ctx, []string{tReq.sourceText}, tReq.destLang, opts)
+	chars := utf8.RuneCountInString(tReq.sourceText)
+	ctx, span := tracing.Start(ctx, "translateText",
+		kv.String("field", tReq.field),
+		kv.String("backend", tReq.backend),
+		kv.Int("characters", chars),
+	)
+	defer span.End()
+
+	labels := []string{tReq.backend, tReq.sourceLang.String(), tReq.destLang.String()}
+	metrics.TranslationCharacters.WithLabelValues(labels...).Add(float64(chars))
+	countUsage(tReq, chars)
+	start := time.Now()
+
+	translation, err := be.translate(ctx, tReq.sourceText, tReq.sourceLang, tReq.destLang)
+	metrics.TranslationDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
 	if err != nil {
-		log.Fatalf("Translation service error translating [%s]: %s", tReq.sourceText, err)
+		metrics.TranslationErrors.WithLabelValues(labels...).Inc()
+		span.RecordError(ctx, err)
+		return tResponse{
+			ID:    tReq.ID,
+			field: tReq.field,
+			err:   fmt.Errorf("translation service error translating [%s]: %s", tReq.sourceText, err),
+		}
 	}
 
 	return tResponse{
 		ID:          tReq.ID,
 		field:       tReq.field,
-		translation: resp[0].Text,
+		translation: translation,
 	}
 }
-
-// func translateText(ctx context.Context, tReq TRequest) TResponse {
-// req, err := http.NewRequest("GET", "https://translate.googleapis.com/translate_a/single", nil)
-// if err != nil {
-// 	log.Fatalln(err)
-// }
-
-// q := req.URL.Query()
-// q.Add("client", "gtx")
-// q.Add("sl", "en")
-// q.Add("tl", "it")
-// q.Add("dt", "t")
-// q.Add("q", tReq.sourceText)
-// req.URL.RawQuery = q.Encode()
-
-// client := &http.Client{}
-
-// r, err := client.Do(req)
-// if err != nil {
-// 	log.Fatalln(err)
-// }
-// defer r.Body.Close()
-
-// var resp []interface{}
-// err = json.NewDecoder(r.Body).Decode((&resp))
-// if err != nil {
-// 	log.Fatalln(err)
-// }
-
-// return TResponse{
-// 	ID:          tReq.ID,
-// 	field:       tReq.field,
-// 	translation: resp[0].([]interface{})[0].([]interface{})[0].(string),
-// }
-// }